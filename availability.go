@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultInStockMarkers are texts that indicate a product can be bought
+var defaultInStockMarkers = []string{
+	"add to cart", "add to basket", "add to bag", "buy now", "in stock",
+	"in den warenkorb", "auf lager", "sofort lieferbar",
+	"ajouter au panier", "en stock",
+	"añadir a la cesta", "aggiungi al carrello", "disponibile",
+}
+
+// defaultOutOfStockMarkers are texts that indicate a product can't be bought
+// They take precedence over in-stock markers, which often appear in unrelated page sections
+var defaultOutOfStockMarkers = []string{
+	"currently unavailable", "out of stock", "not in stock", "no longer in stock", "sold out",
+	"temporarily unavailable", "not available",
+	"nicht verfügbar", "ausverkauft", "derzeit nicht auf lager",
+	"nicht lieferbar", "rupture de stock", "épuisé", "indisponible",
+	"agotado", "non disponibile", "esaurito",
+}
+
+// negations are words that turn an in-stock marker right after them into the opposite
+var negations = []string{"not", "no", "nicht", "kein", "keine", "pas", "non", "no longer", "nicht mehr"}
+
+// availabilityScript reads the schema.org availability from microdata or JSON-LD, if the page has any
+const availabilityScript = `() => {
+	const el = document.querySelector('[itemprop="availability"]');
+	if (el) {
+		return el.getAttribute('href') || el.getAttribute('content') || el.textContent || '';
+	}
+	for (const script of document.querySelectorAll('script[type="application/ld+json"]')) {
+		const match = script.textContent.match(/"availability"\s*:\s*"([^"]*)"/);
+		if (match) {
+			return match[1];
+		}
+	}
+	return '';
+}`
+
+// EvaluateAvailability decides whether a product is in stock
+// Structured schema.org data wins over text markers, out-of-stock markers win over in-stock ones
+func EvaluateAvailability(content, structured string, availabilityConfig *AvailabilityConfig) (bool, []string, error) {
+	// Trust the shop's own structured data when it is present
+	switch {
+	case strings.Contains(structured, "OutOfStock"), strings.Contains(structured, "SoldOut"),
+		strings.Contains(structured, "Discontinued"):
+		return false, []string{fmt.Sprintf("out of stock (structured data: %s)", structured)}, nil
+	case strings.Contains(structured, "InStock"), strings.Contains(structured, "LimitedAvailability"),
+		strings.Contains(structured, "OnlineOnly"):
+		return true, []string{fmt.Sprintf("in stock (structured data: %s)", structured)}, nil
+	}
+
+	inStock, outOfStock := availabilityMarkers(availabilityConfig)
+	lowerContent := strings.ToLower(content)
+
+	for _, marker := range outOfStock {
+		if strings.Contains(lowerContent, strings.ToLower(marker)) {
+			return false, []string{fmt.Sprintf("out of stock (marker: %s)", marker)}, nil
+		}
+	}
+	for _, marker := range inStock {
+		found, negated := findMarker(lowerContent, strings.ToLower(marker))
+		if found {
+			return true, []string{fmt.Sprintf("in stock (marker: %s)", marker)}, nil
+		}
+		if negated {
+			return false, []string{fmt.Sprintf("out of stock (negated marker: %s)", marker)}, nil
+		}
+	}
+
+	return false, nil, fmt.Errorf("availability could not be determined, no known marker found")
+}
+
+// findMarker looks for a marker as whole words, telling apart plain and negated occurrences
+// "in stock" doesn't count inside "not in stock" or "restocking"
+func findMarker(content, marker string) (found, negated bool) {
+	for offset := 0; marker != ""; {
+		index := strings.Index(content[offset:], marker)
+		if index < 0 {
+			return false, negated
+		}
+		start, end := offset+index, offset+index+len(marker)
+		offset = start + 1
+		if !wordBoundary(content, start, end) {
+			continue
+		}
+		if isNegated(content[:start]) {
+			negated = true
+			continue
+		}
+		return true, negated
+	}
+	return false, false
+}
+
+// wordBoundary reports whether content[start:end] isn't part of a longer word
+func wordBoundary(content string, start, end int) bool {
+	before, _ := utf8.DecodeLastRuneInString(content[:start])
+	after, _ := utf8.DecodeRuneInString(content[end:])
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+	return (start == 0 || !isWord(before)) && (end == len(content) || !isWord(after))
+}
+
+// isNegated reports whether the text before a marker ends with a negation word
+func isNegated(before string) bool {
+	before = strings.TrimRightFunc(before, unicode.IsSpace)
+	for _, negation := range negations {
+		if strings.HasSuffix(before, negation) && wordBoundary(before, len(before)-len(negation), len(before)) {
+			return true
+		}
+	}
+	return false
+}
+
+// availabilityMarkers returns the markers to check, extending or replacing the built-in set
+func availabilityMarkers(availabilityConfig *AvailabilityConfig) ([]string, []string) {
+	if availabilityConfig == nil {
+		return defaultInStockMarkers, defaultOutOfStockMarkers
+	}
+	if availabilityConfig.ReplaceDefaults {
+		return availabilityConfig.InStock, availabilityConfig.OutOfStock
+	}
+	return append(availabilityConfig.InStock, defaultInStockMarkers...),
+		append(availabilityConfig.OutOfStock, defaultOutOfStockMarkers...)
+}
@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestEvaluateAvailabilityMarkers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		inStock bool
+	}{
+		{"in stock", "Blue Widget - In stock, ships tomorrow", true},
+		{"not in stock", "Blue Widget - Not in stock", false},
+		{"currently not in stock", "This item is currently not in stock.", false},
+		{"no longer in stock", "Sorry, no longer in stock", false},
+		{"negation before custom spacing", "Status: NOT\n  in stock", false},
+		{"out of stock wins", "Out of stock. Similar items: in stock", false},
+		{"german negated", "Artikel ist nicht auf Lager", false},
+		{"german", "Auf Lager, sofort lieferbar", true},
+		{"part of a word", "Restocking soon. Add to cart", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inStock, _, err := EvaluateAvailability(test.content, "", nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if inStock != test.inStock {
+				t.Errorf("EvaluateAvailability(%q) = %v, want %v", test.content, inStock, test.inStock)
+			}
+		})
+	}
+}
+
+func TestEvaluateAvailabilityNegatedCustomMarker(t *testing.T) {
+	availabilityConfig := &AvailabilityConfig{InStock: []string{"lieferbar"}, ReplaceDefaults: true}
+
+	inStock, details, err := EvaluateAvailability("Derzeit nicht lieferbar", "", availabilityConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inStock {
+		t.Errorf("negated custom marker reported in stock: %v", details)
+	}
+}
+
+func TestEvaluateAvailabilityNoMarker(t *testing.T) {
+	if _, _, err := EvaluateAvailability("Nothing to see here", "", nil); err == nil {
+		t.Error("expected an error when no marker is found")
+	}
+}
@@ -1,123 +1,712 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"regexp"
+	"log"
+	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
 )
 
 // Browser handles web operations using embedded browser
 // Wraps go-rod browser instance for headless web content fetching
 type Browser struct {
 	browser *rod.Browser
+	*searchState
+
+	tabsMu sync.Mutex
+	tabs   map[string]*browserTab // Tabs kept open per target with reuse_page
 }
 
 // NewBrowser creates a new browser instance
-func NewBrowser() *Browser {
+func NewBrowser(config *Config) (*Browser, error) {
 	// Start headless Chromium browser and connect to control interface
 	l := launcher.New().Headless(true)
-	url := l.MustLaunch()
-	browser := rod.New().ControlURL(url).MustConnect()
+	if config.UserDataDir != "" {
+		// Keep cookies and local storage in a persistent profile across restarts
+		l = l.UserDataDir(config.UserDataDir)
+	}
+	if proxyURL, err := url.Parse(config.Proxy); err == nil && config.Proxy != "" {
+		// Chromium takes the proxy without credentials, openPage answers its auth challenge
+		l = l.Proxy(proxyURL.Scheme + "://" + proxyURL.Host)
+	}
+	if config.TLS.MinVersion == "1.3" {
+		// Chromium already requires TLS 1.2 and picks its own cipher suites, only 1.3 can be enforced
+		l = l.Set("ssl-version-min", "tls1.3")
+	}
+	url, err := l.Launch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to launch browser: %w", err)
+	}
+	browser := rod.New().ControlURL(url)
+	if err := browser.Connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to browser: %w", err)
+	}
 
 	return &Browser{
-		browser: browser,
-	}
+		browser:     browser,
+		searchState: newSearchState(),
+		tabs:        make(map[string]*browserTab),
+	}, nil
 }
 
 // Close releases the browser resources
 func (b *Browser) Close() {
 	if b.browser != nil {
-		b.browser.MustClose()
+		if err := b.browser.Close(); err != nil {
+			log.Printf("Failed to close browser: %v", err)
+		}
 	}
 }
 
 // Fetch implements the Client interface for browser-based fetching
 // Creates page, navigates to URL, extracts content, and searches for patterns
-func (b *Browser) Fetch(config *Config) *Result {
-	var content string
-	var err error
+func (b *Browser) Fetch(config *Config) (result *Result) {
+	// A panic inside rod fails this fetch instead of the whole process, the tab is released first
+	defer func() {
+		if r := recover(); r != nil {
+			result = &Result{Error: fmt.Errorf("browser fetch panicked: %v", r)}
+		}
+	}()
+
+	page, load, closePage, err := b.openPage(config)
+	if err != nil {
+		return &Result{Error: err}
+	}
+	defer closePage()
 
-	// Open new browser tab with 30 second timeout
-	page := b.browser.Timeout(30 * time.Second).MustPage()
-	defer page.Close()
+	result = b.searchPage(config, page, load)
+	result.Response = load.response.info()
+	if config.Screenshot != nil {
+		result.Screenshot = captureScreenshot(page, config.Screenshot)
+	}
+	return result
+}
 
-	// Load the specified URL in the browser
-	if err = page.Navigate(config.URL); err != nil {
-		return &Result{
-			Error: fmt.Errorf("failed to navigate to page: %w", err),
-		}
+// searchPage extracts content from a loaded page and searches it
+func (b *Browser) searchPage(config *Config, page *rod.Page, load *pageLoad) *Result {
+	var content string
+
+	// Named checks each extract and search their own region of the page
+	if len(config.SearchConfig.Checks) > 0 {
+		return b.runChecks(config, func(xpath string) (*string, error) {
+			return b.checkContent(page, xpath)
+		})
 	}
 
-	// Wait for page to finish loading including JavaScript execution
-	page.MustWaitLoad()
+	// Relations compare the values of two elements
+	if strings.ToLower(config.SearchConfig.Type) == "relation" {
+		return runRelation(config, func(xpath string) (*string, error) {
+			return b.checkContent(page, xpath)
+		})
+	}
 
-	// Extract text content using XPath selector or entire page body
-	if config.SearchConfig.XPath != "" {
-		// Find elements matching the XPath expression
-		elements, err := page.ElementsX(config.SearchConfig.XPath)
+	// Search the value pre_script returned, or extract content with a regex over the raw source,
+	// an XPath or CSS selector or the entire page body
+	if load.scriptResult != nil {
+		content = *load.scriptResult
+	} else if config.SearchConfig.ExtractRegex != "" {
+		raw, err := page.HTML()
+		if err != nil {
+			return &Result{
+				Error: fmt.Errorf("failed to read page source: %w", err),
+			}
+		}
+		if content, err = extractWithRegex(raw, &config.SearchConfig); err != nil {
+			return &Result{Error: err}
+		}
+	} else if config.SearchConfig.selector() != "" {
+		// Find elements matching the XPath or CSS selector
+		elements, err := findElements(page, &config.SearchConfig)
 		if err != nil {
 			return &Result{
-				Error: fmt.Errorf("failed to find XPath elements: %w", err),
+				Error: fmt.Errorf("failed to find elements for %s: %w", config.SearchConfig.describeSelector(), err),
 			}
 		}
 
+		// Element searches only care whether the selector matches, not what it contains
+		if strings.ToLower(config.SearchConfig.Type) == "element" {
+			result := &Result{Found: len(elements) > 0}
+			if result.Found {
+				text, err := elements[0].Text()
+				if err != nil {
+					return &Result{Error: fmt.Errorf("failed to read element text: %w", err)}
+				}
+				result.Content = text
+				result.Matches = []string{fmt.Sprintf("%d matching element(s)", len(elements))}
+			}
+			return result
+		}
+
 		if len(elements) > 0 {
-			content = elements[0].MustText()
+			if content, err = selectedContent(elements, &config.SearchConfig); err != nil {
+				return &Result{Error: err}
+			}
+		} else {
+			// Decide how a selector that matches nothing is handled
+			switch config.SearchConfig.OnEmptyExtraction {
+			case "fallback-body":
+				if content, err = bodyText(page); err != nil {
+					return &Result{Error: err}
+				}
+			case "empty":
+				content = ""
+			default:
+				return &Result{
+					Error: fmt.Errorf("%s matched no elements", config.SearchConfig.describeSelector()),
+				}
+			}
 		}
 	} else {
 		// Get all text content from the page body element
-		content = page.MustElement("body").MustText()
+		text, err := bodyText(page)
+		if err != nil {
+			return &Result{Error: err}
+		}
+		content = text
+	}
+	if config.SearchConfig.ExtractRegex == "" {
+		content = normalizeSpaces(content)
+	}
+
+	// Read navigation timings, only fatal when searching on them
+	metrics, err := collectPerformanceMetrics(page)
+	if err != nil && strings.ToLower(config.SearchConfig.Type) == "perf" {
+		return &Result{
+			Content: content,
+			Error:   fmt.Errorf("failed to collect performance metrics: %w", err),
+		}
+	}
+
+	data := &pageData{content: content, metrics: metrics}
+
+	// Read schema.org availability for availability searches
+	if strings.ToLower(config.SearchConfig.Type) == "availability" {
+		structured, err := page.Eval(availabilityScript)
+		if err != nil {
+			return &Result{
+				Content: content,
+				Error:   fmt.Errorf("failed to read structured availability: %w", err),
+			}
+		}
+		data.availability = structured.Value.Str()
+	}
+
+	// Collect link or image URLs for links searches
+	if strings.ToLower(config.SearchConfig.Type) == "links" {
+		links, err := page.Eval(linksScript, config.SearchConfig.LinkSource)
+		if err != nil {
+			return &Result{
+				Content: content,
+				Error:   fmt.Errorf("failed to collect links: %w", err),
+			}
+		}
+		if err := links.Value.Unmarshal(&data.links); err != nil {
+			return &Result{
+				Content: content,
+				Error:   fmt.Errorf("failed to read links: %w", err),
+			}
+		}
+	}
+
+	// Named searches each run their own pattern on the extracted text
+	if len(config.SearchConfig.Searches) > 0 {
+		return b.runSearches(config, data)
 	}
 
 	// Search the extracted text using configured pattern type
-	found, matches, err := b.performSearch(content, &config.SearchConfig)
+	found, matches, err := b.performSearch(config.stateKey(), data, &config.SearchConfig)
 	if err != nil {
 		return &Result{
 			Content: content,
 			Error:   fmt.Errorf("search failed: %w", err),
+			Metrics: metrics,
 		}
 	}
 
 	return &Result{
-		Found:   found,
-		Content: content,
-		Error:   nil,
-		Matches: matches,
-	}
-}
-
-// performSearch executes search based on configuration
-// Handles string, regex, and compound pattern matching
-func (b *Browser) performSearch(content string, searchConfig *SearchConfig) (bool, []string, error) {
-	switch strings.ToLower(searchConfig.Type) {
-	case "string":
-		// Check if pattern text appears anywhere in content
-		found := strings.Contains(content, searchConfig.Pattern)
-		matches := []string{}
-		if found {
-			matches = []string{searchConfig.Pattern}
-		}
-		return found, matches, nil
-	case "regex":
-		// Compile regex and find all matches in content
-		re, err := regexp.Compile(searchConfig.Pattern)
+		Found:    found,
+		Content:  content,
+		Error:    nil,
+		Matches:  matches,
+		Metrics:  metrics,
+		Patterns: compoundDetails(&config.SearchConfig, content),
+	}
+}
+
+// domSettleScript resolves once no DOM mutation happened for quiet milliseconds
+// Resolves false when the page keeps mutating until the max wait elapses
+const domSettleScript = `(quiet, max) => new Promise(resolve => {
+	let quietTimer;
+	const finish = settled => {
+		observer.disconnect();
+		clearTimeout(quietTimer);
+		clearTimeout(maxTimer);
+		resolve(settled);
+	};
+	const observer = new MutationObserver(() => {
+		clearTimeout(quietTimer);
+		quietTimer = setTimeout(() => finish(true), quiet);
+	});
+	observer.observe(document, { subtree: true, childList: true, attributes: true, characterData: true });
+	quietTimer = setTimeout(() => finish(true), quiet);
+	const maxTimer = setTimeout(() => finish(false), max);
+})`
+
+// performanceScript reads navigation and paint timings relative to navigation start
+const performanceScript = `() => {
+	const nav = performance.getEntriesByType('navigation')[0];
+	const fcp = performance.getEntriesByName('first-contentful-paint')[0];
+	return {
+		ttfb: nav ? nav.responseStart : 0,
+		dom_content_loaded: nav ? nav.domContentLoadedEventEnd : 0,
+		load_time: nav ? nav.loadEventEnd : 0,
+		first_contentful_paint: fcp ? fcp.startTime : 0,
+	};
+}`
+
+// collectPerformanceMetrics evaluates the performance timing API in the loaded page
+func collectPerformanceMetrics(page *rod.Page) (*PerformanceMetrics, error) {
+	obj, err := page.Eval(performanceScript)
+	if err != nil {
+		return nil, err
+	}
+
+	var metrics PerformanceMetrics
+	if err := obj.Value.Unmarshal(&metrics); err != nil {
+		return nil, err
+	}
+	return &metrics, nil
+}
+
+// findElements returns the elements matching the search's XPath or CSS selector
+func findElements(page *rod.Page, searchConfig *SearchConfig) (rod.Elements, error) {
+	if searchConfig.CSS != "" {
+		return page.Elements(searchConfig.CSS)
+	}
+	return page.ElementsX(searchConfig.XPath)
+}
+
+// selectedContent returns the content of the first selected element
+// With match_all_elements the content of every selected element is joined by newlines
+func selectedContent(elements rod.Elements, searchConfig *SearchConfig) (string, error) {
+	if !searchConfig.MatchAllElements {
+		elements = elements[:1]
+	}
+
+	texts := make([]string, 0, len(elements))
+	for _, element := range elements {
+		text, err := elementContent(element, searchConfig)
 		if err != nil {
-			return false, nil, fmt.Errorf("invalid regex pattern: %w", err)
+			return "", err
 		}
-		matches := re.FindAllString(content, -1)
-		return len(matches) > 0, matches, nil
-	case "compound":
-		// Parse and evaluate boolean pattern expressions
-		compound, err := ParseCompoundPattern(searchConfig.Pattern)
+		texts = append(texts, text)
+	}
+	return strings.Join(texts, "\n"), nil
+}
+
+// elementContent returns the text of a selected element, or the configured attribute instead
+func elementContent(element *rod.Element, searchConfig *SearchConfig) (string, error) {
+	if searchConfig.Attribute == "" {
+		return element.Text()
+	}
+
+	value, err := element.Attribute(searchConfig.Attribute)
+	if err != nil {
+		return "", fmt.Errorf("failed to read attribute %q: %w", searchConfig.Attribute, err)
+	}
+	if value == nil {
+		return "", fmt.Errorf("element matched by %s has no %q attribute", searchConfig.describeSelector(), searchConfig.Attribute)
+	}
+	return *value, nil
+}
+
+// bodyText returns the text of the page's body element
+func bodyText(page *rod.Page) (string, error) {
+	body, err := page.Element("body")
+	if err != nil {
+		return "", fmt.Errorf("failed to find page body: %w", err)
+	}
+	text, err := body.Text()
+	if err != nil {
+		return "", fmt.Errorf("failed to read page body: %w", err)
+	}
+	return text, nil
+}
+
+// checkContent returns the text a check searches, nil when its XPath matches nothing
+func (b *Browser) checkContent(page *rod.Page, xpath string) (*string, error) {
+	if xpath == "" {
+		text, err := bodyText(page)
+		text = normalizeSpaces(text)
+		return &text, err
+	}
+
+	elements, err := page.ElementsX(xpath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find XPath elements: %w", err)
+	}
+	if len(elements) == 0 {
+		return nil, nil
+	}
+	text, err := elements[0].Text()
+	text = normalizeSpaces(text)
+	return &text, err
+}
+
+// browserTab is a page with its event handlers installed
+// With reuse_page it is kept between fetches of the same monitor and navigated again
+type browserTab struct {
+	page     *rod.Page
+	config   *Config
+	response *responseRecorder
+	close    func()
+}
+
+// newTab opens a browser tab and installs the dialog, proxy authentication and response handlers
+// The handlers run until the tab is closed
+func (b *Browser) newTab(config *Config) (*browserTab, error) {
+	page, err := b.browser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tab: %w", err)
+	}
+
+	// Cancelling the tab's context ends the event handlers below once it is closed
+	ctx, cancel := context.WithCancel(context.Background())
+	page = page.Context(ctx)
+
+	// The Page domain reports dialogs and the Network domain the response of the main document
+	restorePage := page.EnableDomain(&proto.PageEnable{})
+	restoreNetwork := page.EnableDomain(&proto.NetworkEnable{})
+	tab := &browserTab{
+		page:     page,
+		config:   config,
+		response: &responseRecorder{},
+		close: func() {
+			restoreNetwork()
+			restorePage()
+			page.Close()
+			cancel()
+		},
+	}
+
+	// Answer JavaScript dialogs automatically so they can't stall navigation
+	go page.EachEvent(func(e *proto.PageJavascriptDialogOpening) {
+		_ = proto.PageHandleJavaScriptDialog{
+			Accept:     config.Dialogs.Action == "accept",
+			PromptText: config.Dialogs.PromptText,
+		}.Call(page)
+	})()
+
+	// Log in to an authenticating proxy with the credentials from its URL
+	if proxyURL, err := url.Parse(config.Proxy); err == nil && proxyURL.User != nil {
+		if err := (proto.FetchEnable{HandleAuthRequests: true}).Call(page); err != nil {
+			tab.close()
+			return nil, fmt.Errorf("failed to enable proxy authentication: %w", err)
+		}
+		password, _ := proxyURL.User.Password()
+		go page.EachEvent(func(e *proto.FetchRequestPaused) {
+			_ = proto.FetchContinueRequest{RequestID: e.RequestID}.Call(page)
+		}, func(e *proto.FetchAuthRequired) {
+			// Challenges of the page itself get Chromium's default handling
+			response := &proto.FetchAuthChallengeResponse{Response: proto.FetchAuthChallengeResponseResponseDefault}
+			if e.AuthChallenge.Source == proto.FetchAuthChallengeSourceProxy {
+				response = &proto.FetchAuthChallengeResponse{
+					Response: proto.FetchAuthChallengeResponseResponseProvideCredentials,
+					Username: proxyURL.User.Username(),
+					Password: password,
+				}
+			}
+			_ = proto.FetchContinueWithAuth{RequestID: e.RequestID, AuthChallengeResponse: response}.Call(page)
+		})()
+	}
+
+	// Record the response of the main document, after redirects
+	go page.EachEvent(func(e *proto.NetworkResponseReceived) {
+		if e.Type == proto.NetworkResourceTypeDocument && e.FrameID == page.FrameID {
+			tab.response.record(e.Response)
+		}
+	})()
+
+	return tab, nil
+}
+
+// acquireTab returns the tab to load a monitor's page in
+// A reused tab is replaced when the monitor's config changed, e.g. after a reload
+func (b *Browser) acquireTab(config *Config) (*browserTab, error) {
+	if !config.ReusePage {
+		return b.newTab(config)
+	}
+
+	b.tabsMu.Lock()
+	defer b.tabsMu.Unlock()
+
+	key := config.stateKey()
+	if tab, ok := b.tabs[key]; ok {
+		if tab.config == config {
+			return tab, nil
+		}
+		tab.close()
+		delete(b.tabs, key)
+	}
+
+	tab, err := b.newTab(config)
+	if err != nil {
+		return nil, err
+	}
+	b.tabs[key] = tab
+	return tab, nil
+}
+
+// releaseTab closes a tab, or parks a reused one on a blank page so the site's scripts stop running
+// A tab that failed is always closed, the next fetch then starts with a fresh one
+func (b *Browser) releaseTab(tab *browserTab, failed bool) {
+	if !tab.config.ReusePage {
+		tab.close()
+		return
+	}
+
+	if !failed && tab.page.Timeout(time.Duration(tab.config.Timeout)*time.Second).Navigate("about:blank") == nil {
+		return
+	}
+
+	b.tabsMu.Lock()
+	defer b.tabsMu.Unlock()
+	if b.tabs[tab.config.stateKey()] == tab {
+		delete(b.tabs, tab.config.stateKey())
+	}
+	tab.close()
+}
+
+// closeStaleTabs closes the reused tabs of targets that a reload removed or no longer reuse their page
+func (b *Browser) closeStaleTabs(monitors []*Config) {
+	configured := make(map[string]bool, len(monitors))
+	for _, monitor := range monitors {
+		if monitor.ReusePage {
+			configured[monitor.stateKey()] = true
+		}
+	}
+
+	b.tabsMu.Lock()
+	defer b.tabsMu.Unlock()
+	for key, tab := range b.tabs {
+		if !configured[key] {
+			tab.close()
+			delete(b.tabs, key)
+		}
+	}
+}
+
+// openPage loads the configured URL in a tab and waits until it is ready to read
+// The returned function releases the tab
+func (b *Browser) openPage(config *Config) (*rod.Page, *pageLoad, func(), error) {
+	tab, err := b.acquireTab(config)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Everything done with the tab during this fetch shares the configured timeout
+	page := tab.page.Timeout(time.Duration(config.Timeout) * time.Second)
+	closePage := func() {
+		page.CancelTimeout()
+		b.releaseTab(tab, false)
+	}
+	failPage := func() {
+		page.CancelTimeout()
+		b.releaseTab(tab, true)
+	}
+	response := tab.response
+	response.reset()
+
+	// Send configured headers with every request of the page
+	if len(config.Headers) > 0 {
+		headers := make([]string, 0, 2*len(config.Headers))
+		for name, value := range config.Headers {
+			headers = append(headers, name, value)
+		}
+		if _, err := page.SetExtraHeaders(headers); err != nil {
+			failPage()
+			return nil, nil, nil, fmt.Errorf("failed to set headers: %w", err)
+		}
+	}
+
+	// Install configured cookies such as a login session before the first request
+	if len(config.Cookies) > 0 {
+		if err := page.SetCookies(browserCookies(config.Cookies)); err != nil {
+			failPage()
+			return nil, nil, nil, fmt.Errorf("failed to set cookies: %w", err)
+		}
+	}
+
+	// Load the specified URL in the browser, retrying DNS failures with backoff
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		err := page.Navigate(config.URL)
+		if err == nil {
+			break
+		}
+
+		if !isDNSFailure(err) {
+			failPage()
+			return nil, nil, nil, fmt.Errorf("failed to navigate to page: %w", &TransientError{Err: err})
+		}
+
+		if attempt >= config.DNSRetries {
+			failPage()
+			return nil, nil, nil, fmt.Errorf("failed to navigate to page: %w", &DNSError{Err: err})
+		}
+		time.Sleep(time.Duration(1<<attempt) * time.Second)
+	}
+
+	// Wait for page to finish loading including JavaScript execution
+	if err := page.WaitLoad(); err != nil {
+		failPage()
+		return nil, nil, nil, fmt.Errorf("failed to wait for page load: %w", &TransientError{Err: err})
+	}
+	response.loaded(time.Since(start))
+
+	// Give lazy content such as prices or countdowns a fixed moment to render
+	if config.RenderDelay > 0 {
+		time.Sleep(time.Duration(config.RenderDelay) * time.Millisecond)
+	}
+
+	// Wait for an element that client-side rendering adds after the load event
+	if config.WaitFor != "" {
+		if err := waitForElement(page, config.WaitFor, time.Duration(config.WaitForTimeout)*time.Millisecond); err != nil {
+			failPage()
+			return nil, nil, nil, err
+		}
+	}
+
+	// Run the configured script, e.g. to dismiss a cookie banner, before the DOM settles
+	load := &pageLoad{response: response}
+	if config.PreScript != "" {
+		scriptResult, err := runPreScript(page, config)
+		if err != nil {
+			failPage()
+			return nil, nil, nil, err
+		}
+		load.scriptResult = scriptResult
+	}
+
+	// Let client-side rendering finish before reading the DOM
+	if config.DOMSettle > 0 {
+		settled, err := page.Eval(domSettleScript, config.DOMSettle, config.DOMSettleTimeout)
 		if err != nil {
-			return false, nil, fmt.Errorf("invalid compound pattern: %w", err)
+			failPage()
+			return nil, nil, nil, fmt.Errorf("failed to wait for DOM to settle: %w", err)
 		}
-		return EvaluateCompoundPattern(compound, content)
-	default:
-		return false, nil, fmt.Errorf("unsupported search type: %s", searchConfig.Type)
+		if !settled.Value.Bool() {
+			log.Printf("DOM still changing after %dms, extracting anyway", config.DOMSettleTimeout)
+		}
+	}
+
+	return page, load, closePage, nil
+}
+
+// pageLoad holds what openPage learned while loading a page
+type pageLoad struct {
+	response     *responseRecorder
+	scriptResult *string // Value returned by pre_script, only kept with pre_script_content
+}
+
+// runPreScript runs pre_script as the body of an async function, so it may await and return a value
+// The value is returned as text when pre_script_content is set, strings as is and anything else as JSON
+func runPreScript(page *rod.Page, config *Config) (*string, error) {
+	result, err := page.Eval("async () => {\n" + config.PreScript + "\n}")
+	if err != nil {
+		return nil, fmt.Errorf("pre_script failed: %w", err)
+	}
+	if !config.PreScriptContent {
+		return nil, nil
+	}
+
+	if result.Type == proto.RuntimeRemoteObjectTypeUndefined {
+		return nil, fmt.Errorf("pre_script returned nothing, but pre_script_content is set")
+	}
+	content := result.Value.Str()
+	if result.Type != proto.RuntimeRemoteObjectTypeString {
+		content = result.Value.JSON("", "")
+	}
+	return &content, nil
+}
+
+// waitForElement waits until an element matching an XPath or CSS selector is on the page
+// Selectors starting with / or ( are XPaths, anything else is CSS
+func waitForElement(page *rod.Page, selector string, timeout time.Duration) error {
+	waitPage := page.Timeout(timeout)
+	defer waitPage.CancelTimeout()
+
+	var err error
+	if strings.HasPrefix(selector, "/") || strings.HasPrefix(selector, "(") {
+		_, err = waitPage.ElementX(selector)
+	} else {
+		_, err = waitPage.Element(selector)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("wait_for %q matched no element within %v", selector, timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to wait for %q: %w", selector, err)
+	}
+	return nil
+}
+
+// responseRecorder keeps the response of a page's main document as reported by the Network domain
+// Events arrive on their own goroutine, so access is guarded
+type responseRecorder struct {
+	mu       sync.Mutex
+	response *ResponseInfo
+	duration time.Duration
+}
+
+// record stores a document response, the last one wins so redirects report their target
+func (r *responseRecorder) record(response *proto.NetworkResponse) {
+	header := make(http.Header, len(response.Headers))
+	for name, value := range response.Headers {
+		// Chromium joins repeated headers with newlines
+		for _, line := range strings.Split(value.Str(), "\n") {
+			header.Add(name, line)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.response = &ResponseInfo{StatusCode: response.Status, URL: response.URL, Header: header}
+}
+
+// loaded stores how long the page took to load
+func (r *responseRecorder) loaded(duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.duration = duration
+}
+
+// reset forgets the response of an earlier fetch in a reused tab
+func (r *responseRecorder) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.response = nil
+	r.duration = 0
+}
+
+// info returns the recorded response, nil when no document response was seen
+func (r *responseRecorder) info() *ResponseInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.response == nil {
+		return nil
 	}
+	info := *r.response
+	info.Duration = float64(r.duration.Microseconds()) / 1000
+	return &info
 }
@@ -1,5 +1,12 @@
 package main
 
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
 // Client interface for different fetch methods
 // Defines contract for web content fetching and resource cleanup
 type Client interface {
@@ -10,8 +17,103 @@ type Client interface {
 // Result holds the result of a fetch operation
 // Stores whether patterns matched, extracted content, and any errors
 type Result struct {
-	Found   bool
-	Content string
-	Error   error
-	Matches []string // Regex matches found in content
+	Found    bool
+	Content  string
+	Error    error
+	Matches  []string // Regex matches found in content
+	Metrics  *PerformanceMetrics
+	Checks   []CheckResult  // Per-check outcome of a multi-check search, sorted by name
+	Searches []SearchResult // Per-search outcome of named searches, in config order
+	Patterns []MatchDetail  // Per-sub-pattern outcome of a compound search
+	Response *ResponseInfo  // HTTP response of the page, nil when none was received
+
+	Heartbeat []string // Status line per monitor, only set for heartbeat notifications
+
+	Screenshot     []byte // PNG of the page, only captured by the browser when screenshot is configured
+	ScreenshotFile string // Where the screenshot was saved once a notification was sent for it
+}
+
+// ResponseInfo describes the HTTP response the page was loaded from
+// Headers are left out of xml output, which can't represent a map
+type ResponseInfo struct {
+	StatusCode int         `json:"status_code" yaml:"status_code" xml:"status_code"`
+	URL        string      `json:"url" yaml:"url" xml:"url"` // Final URL after redirects
+	Header     http.Header `json:"headers,omitempty" yaml:"headers,omitempty" xml:"-"`
+	Duration   float64     `json:"duration" yaml:"duration" xml:"duration"` // Milliseconds until the page was loaded
+}
+
+// CheckResult holds the outcome of one named check
+type CheckResult struct {
+	Name  string `json:"name" yaml:"name" xml:"name,attr"`
+	Found bool   `json:"found" yaml:"found" xml:"found,attr"`
+}
+
+// SearchResult holds the outcome of one named search
+type SearchResult struct {
+	Name    string   `json:"name" yaml:"name" xml:"name,attr"`
+	Found   bool     `json:"found" yaml:"found" xml:"found,attr"`
+	Matches []string `json:"matches" yaml:"matches" xml:"match"`
+}
+
+// PerformanceMetrics holds page load timings in milliseconds
+// Collected from the browser's navigation and paint timing entries
+type PerformanceMetrics struct {
+	TTFB                 float64 `json:"ttfb" yaml:"ttfb" xml:"ttfb"`
+	DOMContentLoaded     float64 `json:"dom_content_loaded" yaml:"dom_content_loaded" xml:"dom_content_loaded"`
+	LoadTime             float64 `json:"load_time" yaml:"load_time" xml:"load_time"`
+	FirstContentfulPaint float64 `json:"first_contentful_paint" yaml:"first_contentful_paint" xml:"first_contentful_paint"`
+}
+
+// Metric returns the value of a named metric as used in perf patterns
+func (m *PerformanceMetrics) Metric(name string) (float64, bool) {
+	switch name {
+	case "ttfb":
+		return m.TTFB, true
+	case "dom_content_loaded":
+		return m.DOMContentLoaded, true
+	case "load_time":
+		return m.LoadTime, true
+	case "first_contentful_paint":
+		return m.FirstContentfulPaint, true
+	default:
+		return 0, false
+	}
+}
+
+// DNSError marks a fetch failure caused by name resolution
+// Transient resolver outages are retried and alerted on separately from other errors
+type DNSError struct {
+	Err error
+}
+
+func (e *DNSError) Error() string {
+	return "DNS resolution failed: " + e.Err.Error()
+}
+
+func (e *DNSError) Unwrap() error {
+	return e.Err
+}
+
+// TransientError marks a fetch failure that may succeed when retried
+// Covers network errors, failed navigations and 5xx responses, but not configuration mistakes
+type TransientError struct {
+	Err error
+}
+
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// isDNSFailure reports whether an error was caused by name resolution
+// Recognizes Go resolver errors and Chromium's net::ERR_NAME_NOT_RESOLVED
+func isDNSFailure(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	return strings.Contains(err.Error(), "ERR_NAME_NOT_RESOLVED")
 }
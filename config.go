@@ -1,30 +1,314 @@
 package main
 
 import (
+	"bytes"
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"net/mail"
 	"os"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 // Config holds the application configuration
 type Config struct {
+	Name          string        `json:"name,omitempty"` // Optional label used in logs and messages
 	URL           string        `json:"url"`
+	FetchMode     string        `json:"fetch_mode"` // "browser", "http" or "github"
+	GitHub        *GitHubConfig `json:"github,omitempty"`
 	SearchConfig  SearchConfig  `json:"search"`
 	Notifications Notifications `json:"notifications"`
 	Interval      int           `json:"interval"`
+	Timeout       int           `json:"timeout"`         // Seconds a single page fetch may take
+	DNSRetries    int           `json:"dns_retries"`     // Extra navigation attempts after a DNS failure, -1 for none
+	DNSAlertAfter int           `json:"dns_alert_after"` // Consecutive DNS-failed fetches before notifying
+	MaxRetryAfter int           `json:"max_retry_after"` // Longest Retry-After delay in seconds honored with a retry, -1 for none, http fetch mode only
+	MaxBodySize   int           `json:"max_body_size"`   // Largest response body in megabytes read in http fetch mode
+	Dialogs       DialogConfig  `json:"dialogs"`
+
+	FollowMetaRefresh bool `json:"follow_meta_refresh"` // Load the page a <meta http-equiv="refresh"> points to, http fetch mode only
+
+	DOMSettle        int `json:"dom_settle"`         // Milliseconds without DOM mutations before extracting, 0 disables
+	DOMSettleTimeout int `json:"dom_settle_timeout"` // Maximum milliseconds to wait for the DOM to settle
+
+	WaitFor        string `json:"wait_for"`         // XPath or CSS selector of an element to wait for before extracting, browser only
+	WaitForTimeout int    `json:"wait_for_timeout"` // Maximum milliseconds to wait for the wait_for element
+	RenderDelay    int    `json:"render_delay"`     // Milliseconds to pause after the load event before extracting, browser only
+	ReusePage      bool   `json:"reuse_page"`       // Keep each monitor's browser tab open between fetches instead of opening a new one
+
+	PreScript        string `json:"pre_script"`         // JavaScript run in the page before extracting, e.g. to click "load more", browser only
+	PreScriptContent bool   `json:"pre_script_content"` // Search the value pre_script returns instead of extracting from the page
+
+	Headers     map[string]string `json:"headers,omitempty"` // Extra request headers sent by the browser and http fetch modes
+	Cookies     []CookieConfig    `json:"cookies,omitempty"`
+	CookiesFile string            `json:"cookies_file"` // Netscape cookies.txt file, added to cookies
+
+	UserDataDir string `json:"user_data_dir"`           // Persistent Chromium profile, empty for a fresh temporary one
+	UseEnvProxy *bool  `json:"use_env_proxy,omitempty"` // Route http fetch mode through HTTP_PROXY/HTTPS_PROXY/NO_PROXY, defaults to true
+	Proxy       string `json:"proxy"`                   // http, https or socks5 proxy URL with optional credentials, replaces the environment
+
+	StateFile string `json:"state_file"` // Where notify_on "change" keeps content hashes and github mode the last seen version between runs
+
+	AdaptiveInterval *AdaptiveIntervalConfig `json:"adaptive_interval,omitempty"`
+	Jitter           int                     `json:"jitter"` // Percent the delay between checks randomly varies by, 0 disables
+	Retry            *RetryConfig            `json:"retry,omitempty"`
+
+	MaxConsecutiveErrors int `json:"max_consecutive_errors"` // Failed fetches in a row tolerated before exiting, 0 never exits
+
+	WarmupChecks   int `json:"warmup_checks"`   // Fetches after startup that run without notifying
+	WarmupDuration int `json:"warmup_duration"` // Seconds after startup without notifications
+
+	TriggerFile string `json:"trigger_file"` // Scheduled fetches only run when this file allows it
+	TriggerMode string `json:"trigger_mode"` // "exists" or "modified"
+
+	SSHTunnel  *SSHTunnelConfig  `json:"ssh_tunnel,omitempty"`
+	TLS        *TLSConfig        `json:"tls,omitempty"`
+	Screenshot *ScreenshotConfig `json:"screenshot,omitempty"` // Capture the page with every browser fetch, kept when notifying
+
+	Targets []TargetConfig `json:"targets,omitempty"` // Several pages monitored by one process, replacing url and search
+
+	target int // Position in targets, set by Monitors
+}
+
+// TargetConfig defines one monitored page when a config watches several
+// Everything not set here is shared from the top-level config
+type TargetConfig struct {
+	Name         string       `json:"name"`
+	URL          string       `json:"url"`
+	SearchConfig SearchConfig `json:"search"`
+	Interval     int          `json:"interval"` // Defaults to the top-level interval
+	Timeout      int          `json:"timeout"`  // Defaults to the top-level timeout
+	WaitFor      string       `json:"wait_for"` // Defaults to the top-level wait_for
+}
+
+// Monitors returns one config per monitored page
+// A config without targets monitors its own url, each target otherwise gets a copy with its own url and search
+func (c *Config) Monitors() []*Config {
+	if len(c.Targets) == 0 {
+		return []*Config{c}
+	}
+
+	monitors := make([]*Config, 0, len(c.Targets))
+	for _, target := range c.Targets {
+		monitor := *c
+		monitor.Targets = nil
+		monitor.target = len(monitors)
+		monitor.Name = target.Name
+		monitor.URL = target.URL
+		monitor.SearchConfig = target.SearchConfig
+		if target.Interval > 0 {
+			monitor.Interval = target.Interval
+		}
+		if target.Timeout > 0 {
+			monitor.Timeout = target.Timeout
+		}
+		if target.WaitFor != "" {
+			monitor.WaitFor = target.WaitFor
+		}
+		monitors = append(monitors, &monitor)
+	}
+	return monitors
+}
+
+// searchMonitors returns one config per named search, sharing this config's page and extraction
+// Every search gets its own notification state from these configs
+func (c *Config) searchMonitors() []*Config {
+	monitors := make([]*Config, 0, len(c.SearchConfig.Searches))
+	for _, search := range c.SearchConfig.Searches {
+		monitor := *c
+		monitor.Name = search.Name
+		if c.Name != "" {
+			monitor.Name = c.Name + "/" + search.Name
+		}
+		monitor.SearchConfig = c.SearchConfig
+		monitor.SearchConfig.Searches = nil
+		monitor.SearchConfig.Type = search.Type
+		monitor.SearchConfig.Pattern = search.Pattern
+		monitor.SearchConfig.NotifyOn = search.NotifyOn
+
+		// Fetch errors are reported through the page's own config, so escalation stays there
+		monitor.Notifications = c.Notifications.only(search.Channels)
+		monitor.Notifications.Escalation = nil
+		if search.Cooldown > 0 {
+			monitor.Notifications.Cooldown = search.Cooldown
+		}
+		monitors = append(monitors, &monitor)
+	}
+	return monitors
+}
+
+// Label names a monitor in logs and messages, falling back to its URL
+func (c *Config) Label() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.URL
+}
+
+// stateKey identifies a monitor in state kept between checks
+// Targets may share a URL with different selectors or patterns, so their position tells them apart
+func (c *Config) stateKey() string {
+	return fmt.Sprintf("%d/%s", c.target, c.Label())
+}
+
+// AdaptiveIntervalConfig polls faster after a change and slower while a page stays the same
+type AdaptiveIntervalConfig struct {
+	Min    int     `json:"min"`    // Seconds between checks right after a change
+	Max    int     `json:"max"`    // Longest seconds between checks during quiet periods
+	Factor float64 `json:"factor"` // Interval growth per unchanged check
+}
+
+// CookieConfig defines a cookie sent by both fetchers, e.g. a login session
+type CookieConfig struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Domain string `json:"domain"` // A leading dot includes subdomains
+	Path   string `json:"path"`   // Defaults to "/"
+	Secure bool   `json:"secure"`
+}
+
+// RetryConfig retries transient fetch failures with exponential backoff
+type RetryConfig struct {
+	MaxAttempts int `json:"max_attempts"` // Total attempts including the first one
+	BaseDelay   int `json:"base_delay"`   // Milliseconds before the first retry, doubled for every further one
+}
+
+// ScreenshotConfig defines where screenshots of notified page states are saved
+type ScreenshotConfig struct {
+	Dir      string `json:"dir"`       // Directory the PNG files are written to, created if missing
+	FullPage bool   `json:"full_page"` // Capture the whole scrollable page instead of the viewport
+}
+
+// SSHTunnelConfig defines a local port forward through an SSH jump host
+// Point url at local_addr to reach remote_addr from the SSH host's network
+type SSHTunnelConfig struct {
+	Host                  string `json:"host"` // "host:port" of the SSH server
+	User                  string `json:"user"`
+	KeyFile               string `json:"key_file"`
+	KnownHostsFile        string `json:"known_hosts_file"`
+	InsecureIgnoreHostKey bool   `json:"insecure_ignore_host_key"`
+	LocalAddr             string `json:"local_addr"`  // e.g. "127.0.0.1:8080"
+	RemoteAddr            string `json:"remote_addr"` // e.g. "internal-service:80"
+}
+
+// TLSConfig restricts the TLS versions and cipher suites used to fetch pages
+type TLSConfig struct {
+	MinVersion   string   `json:"min_version"`             // "1.0", "1.1", "1.2" or "1.3", defaults to "1.2"
+	CipherSuites []string `json:"cipher_suites,omitempty"` // Go cipher suite names, TLS 1.3 suites can't be restricted
+}
+
+// GitHubConfig defines the repository watched in github fetch mode
+type GitHubConfig struct {
+	Repo   string `json:"repo"`   // "owner/repo"
+	Token  string `json:"token"`  // Optional, raises the API rate limit
+	Source string `json:"source"` // "release" or "tag"
+}
+
+// DialogConfig defines how JavaScript dialogs (alert, confirm, prompt, beforeunload) are answered
+type DialogConfig struct {
+	Action     string `json:"action"`      // "dismiss" or "accept"
+	PromptText string `json:"prompt_text"` // Text entered into prompt dialogs before accepting
 }
 
 // SearchConfig defines what to search for and how
 type SearchConfig struct {
-	Type     string `json:"type"` // "string", "regex", "compound"
-	Pattern  string `json:"pattern"`
-	XPath    string `json:"xpath"`
-	NotifyOn string `json:"notify_on"` // "found" or "not_found"
+	Type      string `json:"type"` // "string", "regex", "compound", "numeric", "perf", "date", "element", "availability", "links", "relation"
+	Pattern   string `json:"pattern"`
+	XPath     string `json:"xpath"`
+	CSS       string `json:"css"`       // CSS selector used instead of xpath
+	Attribute string `json:"attribute"` // Search this attribute of the selected element instead of its text
+
+	MatchAllElements bool   `json:"match_all_elements"` // Search every element the selector matches, joined by newlines
+	CaseInsensitive  bool   `json:"case_insensitive"`   // Ignore case in string, regex and compound patterns, including checks and searches
+	NotifyOn         string `json:"notify_on"`          // "found", "not_found" or "change"
+
+	OnEmptyExtraction string `json:"on_empty_extraction"` // "error", "fallback-body" or "empty"
+	NotifyIf          string `json:"notify_if"`           // Predicate on the first match, e.g. "changed AND value < 200"
+	DateLayout        string `json:"date_layout"`         // Go time layout used by the date search type
+	AttributeMatches  bool   `json:"attribute_matches"`   // Tag compound matches with the sub-pattern that produced them
+	ExtractRegex      string `json:"extract_regex"`       // Regex run over the raw page source instead of XPath/text extraction
+	ExtractGroup      int    `json:"extract_group"`       // Capture group of extract_regex to search, 0 for the whole match
+	JSONPath          string `json:"jsonpath"`            // JSONPath selecting the value to search in a JSON response, http fetch mode only
+	SortMatches       string `json:"sort_matches"`        // "none", "asc", "desc" or "numeric"
+	MatchSelect       string `json:"match_select"`        // "all", "first", "last", "min", "max", "longest" or "shortest"
+	LinkSource        string `json:"link_source"`         // "links" (a href) or "images" (img src) for the links search type
+
+	Window       *WindowConfig          `json:"window,omitempty"`
+	Availability *AvailabilityConfig    `json:"availability,omitempty"`
+	Relation     *RelationConfig        `json:"relation,omitempty"`
+	Checks       map[string]CheckConfig `json:"checks,omitempty"`   // Named checks evaluated against the same page
+	Searches     []NamedSearch          `json:"searches,omitempty"` // Patterns notified separately, sharing one fetch
+}
+
+// needsHTML reports whether the search relies on the page's HTML structure rather than its raw text
+func (s *SearchConfig) needsHTML() bool {
+	switch strings.ToLower(s.Type) {
+	case "element", "availability", "links", "relation":
+		return true
+	}
+	return s.selector() != "" || s.ExtractRegex != "" || len(s.Checks) > 0
+}
+
+// selector returns the configured XPath or CSS selector, empty when neither is set
+func (s *SearchConfig) selector() string {
+	if s.CSS != "" {
+		return s.CSS
+	}
+	return s.XPath
+}
+
+// describeSelector names the selector with its kind for errors, e.g. CSS ".price"
+func (s *SearchConfig) describeSelector() string {
+	if s.CSS != "" {
+		return fmt.Sprintf("CSS %q", s.CSS)
+	}
+	return fmt.Sprintf("XPath %q", s.XPath)
+}
+
+// CheckConfig defines one named selector and pattern evaluated in a multi-check search
+type CheckConfig struct {
+	XPath   string `json:"xpath"` // Optional, the whole page body is searched when empty
+	Type    string `json:"type"`  // "string", "regex" or "compound"
+	Pattern string `json:"pattern"`
+}
+
+// RelationConfig compares the numbers in two elements, e.g. a sale price below the list price
+type RelationConfig struct {
+	Left     string `json:"left"`     // XPath of the left-hand value
+	Operator string `json:"operator"` // "<", "<=", ">", ">=", "==" or "!="
+	Right    string `json:"right"`    // XPath of the right-hand value
 }
 
-// CompoundPattern represents parsed compound search pattern with AND/OR operations
+// NamedSearch is one of several patterns searched in the same extracted content
+// Each search notifies on its own, so different keywords can go to different channels
+type NamedSearch struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"` // "string", "regex" or "compound"
+	Pattern  string   `json:"pattern"`
+	NotifyOn string   `json:"notify_on"`          // "found" or "not_found", defaults to the search's notify_on
+	Channels []string `json:"channels,omitempty"` // Channels to notify, all configured channels when empty
+	Cooldown int      `json:"cooldown"`           // Overrides notifications.cooldown for this search
+}
+
+// AvailabilityConfig customizes the text markers used by the availability search type
+type AvailabilityConfig struct {
+	InStock         []string `json:"in_stock"`
+	OutOfStock      []string `json:"out_of_stock"`
+	ReplaceDefaults bool     `json:"replace_defaults"` // Use only these markers instead of adding to the built-in ones
+}
+
+// WindowConfig requires the notify_on condition to hold in MinCount of the last Size checks
+type WindowConfig struct {
+	Size     int `json:"size"`
+	MinCount int `json:"min_count"`
+}
+
+// CompoundPattern represents parsed compound search pattern with AND/OR/NOT operations
 type CompoundPattern struct {
 	Operator string           // "AND" or "OR"
 	Patterns []PatternElement // Individual patterns or nested compounds
@@ -32,9 +316,32 @@ type CompoundPattern struct {
 
 // PatternElement represents either a single pattern or nested compound pattern
 type PatternElement struct {
-	Type     string // "string", "regex", "compound"
-	Pattern  string
-	Compound *CompoundPattern // For nested compound patterns
+	Type       string // "string", "regex", "compound"
+	Pattern    string
+	Compound   *CompoundPattern // For nested compound patterns
+	IgnoreCase bool             // Set for case_insensitive searches
+	Negated    bool             // Prefixed with NOT, holds when the element doesn't match
+}
+
+// ignoreCase makes every string and regex element of the pattern match regardless of case
+func (c *CompoundPattern) ignoreCase() {
+	for i := range c.Patterns {
+		c.Patterns[i].IgnoreCase = true
+		if c.Patterns[i].Compound != nil {
+			c.Patterns[i].Compound.ignoreCase()
+		}
+	}
+}
+
+// containsFold reports whether pattern occurs in content regardless of case
+// Returns the occurrence as written in the content
+func containsFold(content, pattern string) (string, bool) {
+	re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(pattern))
+	loc := re.FindStringIndex(content)
+	if loc == nil {
+		return "", false
+	}
+	return content[loc[0]:loc[1]], true
 }
 
 // Notifications holds configuration for notification channels
@@ -42,45 +349,368 @@ type Notifications struct {
 	Email   *EmailConfig   `json:"email,omitempty"`
 	Discord *DiscordConfig `json:"discord,omitempty"`
 	Slack   *SlackConfig   `json:"slack,omitempty"`
+	Gotify  *GotifyConfig  `json:"gotify,omitempty"`
+	File    *FileConfig    `json:"file,omitempty"`
+	Exec    *ExecConfig    `json:"exec,omitempty"`
+	Webhook *WebhookConfig `json:"webhook,omitempty"`
+
+	Order              []string `json:"order,omitempty"`       // Channel names in dispatch order
+	StopOnFirstSuccess bool     `json:"stop_on_first_success"` // Skip remaining channels once one delivers
+	Concurrent         bool     `json:"concurrent"`            // Send to all channels in parallel, ignored with stop_on_first_success
+	NotifyPerMatch     bool     `json:"notify_per_match"`      // Send a separate notification for every match
+
+	RateLimits map[string]RateLimitConfig `json:"rate_limits,omitempty"` // Token bucket per channel name
+	Escalation *EscalationConfig          `json:"escalation,omitempty"`
+	Dedup      *DedupConfig               `json:"dedup,omitempty"`
+	Cooldown   int                        `json:"cooldown,omitempty"` // Minutes an unchanged state, errors included, isn't notified again
+	Heartbeat  *HeartbeatConfig           `json:"heartbeat,omitempty"`
+}
+
+// only returns a copy keeping just the named channels, or every channel when names is empty
+func (n Notifications) only(names []string) Notifications {
+	if len(names) == 0 {
+		return n
+	}
+
+	keep := func(name string) bool { return slices.Contains(names, name) }
+	if !keep("email") {
+		n.Email = nil
+	}
+	if !keep("discord") {
+		n.Discord = nil
+	}
+	if !keep("slack") {
+		n.Slack = nil
+	}
+	if !keep("gotify") {
+		n.Gotify = nil
+	}
+	if !keep("file") {
+		n.File = nil
+	}
+	if !keep("exec") {
+		n.Exec = nil
+	}
+	if !keep("webhook") {
+		n.Webhook = nil
+	}
+	n.Order = slices.DeleteFunc(slices.Clone(n.Order), func(name string) bool { return !keep(name) })
+	return n
+}
+
+// filters returns the event filter of every configured channel by name
+func (n Notifications) filters() map[string]*ChannelFilter {
+	filters := map[string]*ChannelFilter{}
+	if n.Email != nil {
+		filters["email"] = &n.Email.ChannelFilter
+	}
+	if n.Discord != nil {
+		filters["discord"] = &n.Discord.ChannelFilter
+	}
+	if n.Slack != nil {
+		filters["slack"] = &n.Slack.ChannelFilter
+	}
+	if n.Gotify != nil {
+		filters["gotify"] = &n.Gotify.ChannelFilter
+	}
+	if n.File != nil {
+		filters["file"] = &n.File.ChannelFilter
+	}
+	if n.Exec != nil {
+		filters["exec"] = &n.Exec.ChannelFilter
+	}
+	if n.Webhook != nil {
+		filters["webhook"] = &n.Webhook.ChannelFilter
+	}
+	return filters
+}
+
+// events returns the top-level notify_on followed by every other event a channel subscribes to
+func (n Notifications) events(notifyOn string) []string {
+	events := []string{notifyOn}
+	for _, event := range []string{"found", "not_found", "change", "error"} {
+		if slices.Contains(events, event) {
+			continue
+		}
+		for _, filter := range n.filters() {
+			if slices.Contains(filter.NotifyOn, event) {
+				events = append(events, event)
+				break
+			}
+		}
+	}
+	return events
+}
+
+// DedupConfig suppresses alerts whose match set equals the last notified one
+type DedupConfig struct {
+	Window int `json:"window"` // Minutes an identical match set stays suppressed, 0 until it changes
+}
+
+// HeartbeatConfig sends a periodic "still running" notification regardless of the pattern state
+type HeartbeatConfig struct {
+	IntervalHours int      `json:"interval_hours"`
+	Channels      []string `json:"channels,omitempty"` // Channels to notify, every configured channel when empty
+}
+
+// EscalationConfig reserves channels for errors that persist
+// Escalation channels are only notified once an error streak reaches either threshold
+type EscalationConfig struct {
+	Channels     []string `json:"channels"`
+	AfterErrors  int      `json:"after_errors"`  // Consecutive failed fetches before escalating
+	AfterMinutes int      `json:"after_minutes"` // Minutes of continuous errors before escalating
+}
+
+// RateLimitConfig defines a token bucket limiting how often a channel may send
+type RateLimitConfig struct {
+	PerMinute int    `json:"per_minute"`
+	Burst     int    `json:"burst"`
+	OnLimit   string `json:"on_limit"` // "wait" or "drop"
 }
 
 // EmailConfig holds SMTP configuration
 type EmailConfig struct {
-	SMTPHost string `json:"smtp_host"`
-	SMTPPort int    `json:"smtp_port"`
-	Username string `json:"username"`
-	Password string `json:"password"`
-	From     string `json:"from"`
-	To       string `json:"to"`
-	Subject  string `json:"subject"`
+	SMTPHost    string       `json:"smtp_host"`
+	SMTPPort    int          `json:"smtp_port"`
+	SMTPServers []SMTPServer `json:"smtp_servers,omitempty"` // Fallback servers tried in order after smtp_host
+	Username    string       `json:"username"`
+	Password    string       `json:"password"`
+	TLSMode     string       `json:"tls_mode"`                       // "starttls" (default), "tls" for implicit TLS or "none"
+	Insecure    bool         `json:"insecure_skip_verify,omitempty"` // Accept any server certificate
+	From        string       `json:"from"`
+	FromName    string       `json:"from_name,omitempty"` // Display name shown for the sender
+	To          AddressList  `json:"to"`
+	Cc          AddressList  `json:"cc,omitempty"`
+	Bcc         AddressList  `json:"bcc,omitempty"` // Receive the alert without being listed in the headers
+	Subject     string       `json:"subject"`
+	HTML        bool         `json:"html,omitempty"` // Send an HTML rendering alongside the plain message
+	Timeout     int          `json:"timeout"`        // Seconds for the whole send across all servers
+	TimestampConfig
+	ChannelFilter
+}
+
+// AddressList holds email addresses, written as a comma-separated string or a JSON array of them
+type AddressList []string
+
+// UnmarshalJSON accepts a single string as well as an array of strings
+func (l *AddressList) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*l = list
+		return nil
+	}
+	var joined string
+	if err := json.Unmarshal(data, &joined); err != nil {
+		return fmt.Errorf("email addresses must be a string or a list of strings")
+	}
+	*l = AddressList{joined}
+	return nil
+}
+
+// addresses parses every entry, an entry may hold several comma-separated addresses
+// Names with commas are fine when quoted, as in "\"Doe, Jane\" <jane@example.com>"
+func (l AddressList) addresses() ([]*mail.Address, error) {
+	var addresses []*mail.Address
+	for _, entry := range l {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		parsed, err := mail.ParseAddressList(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", entry, err)
+		}
+		addresses = append(addresses, parsed...)
+	}
+	return addresses, nil
+}
+
+// SMTPServer holds connection settings for a single SMTP server
+// Credentials and TLS settings default to those of the parent EmailConfig
+type SMTPServer struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	TLSMode  string `json:"tls_mode,omitempty"`
+	Insecure *bool  `json:"insecure_skip_verify,omitempty"`
 }
 
 // DiscordConfig holds Discord webhook configuration
 type DiscordConfig struct {
 	WebhookURL string `json:"webhook_url"`
+	Format     string `json:"format"`  // "text" or "embed"
+	Timeout    int    `json:"timeout"` // Seconds before the request is abandoned
+
+	AttachScreenshot bool `json:"attach_screenshot"` // Upload the page screenshot with the message, requires screenshot
+	TimestampConfig
+	ChannelFilter
 }
 
 // SlackConfig holds Slack webhook configuration
 type SlackConfig struct {
 	WebhookURL string `json:"webhook_url"`
+	Format     string `json:"format"`  // "text" or "blocks"
+	Timeout    int    `json:"timeout"` // Seconds before the request is abandoned
+	TimestampConfig
+	ChannelFilter
+}
+
+// GotifyConfig holds Gotify server configuration
+type GotifyConfig struct {
+	ServerURL string `json:"server_url"`
+	Token     string `json:"token"`
+	Priority  int    `json:"priority"` // Priority for pattern alerts, errors are raised to at least 8
+	Timeout   int    `json:"timeout"`  // Seconds before the request is abandoned
+	TimestampConfig
+	ChannelFilter
+}
+
+// FileConfig holds configuration for appending notifications to a file or named pipe
+type FileConfig struct {
+	Path   string `json:"path"`
+	Format string `json:"format"` // "text" or "json"
+	TimestampConfig
+	ChannelFilter
+}
+
+// ExecConfig holds configuration for running an external program as a notifier
+// The command runs with the process's privileges, so it must be explicitly enabled
+type ExecConfig struct {
+	Enabled bool     `json:"enabled"`
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	Timeout int      `json:"timeout"` // Seconds before the command is killed
+	TimestampConfig
+	ChannelFilter
+}
+
+// WebhookConfig holds configuration for sending notifications to any HTTP endpoint
+// The body is a Go template over the notification record, json of the whole record by default
+type WebhookConfig struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method"` // Defaults to POST
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body"`
+	Timeout int               `json:"timeout"` // Seconds before the request is abandoned
+	TimestampConfig
+	ChannelFilter
+}
+
+// ChannelFilter narrows which events a notification channel receives
+type ChannelFilter struct {
+	NotifyOn []string `json:"notify_on,omitempty"` // "found", "not_found", "change" or "error", defaults to the top-level notify_on and errors
+}
+
+// TimestampConfig controls how a notification channel renders the message timestamp
+type TimestampConfig struct {
+	IncludeTimestamp *bool  `json:"include_timestamp,omitempty"` // Defaults to true
+	TimeFormat       string `json:"time_format,omitempty"`       // Go time layout or "discord:<style>"
+	Timezone         string `json:"timezone,omitempty"`          // IANA name such as "Europe/Berlin", defaults to the local zone
+
+	location *time.Location // Loaded from Timezone by validateConfig
 }
 
 // LoadConfig loads configuration from a JSON file
-func LoadConfig(filename string) (*Config, error) {
-	// Read file contents and unmarshal JSON into config struct
+// In strict mode unknown fields are an error, so misspelled keys don't silently fall back to defaults
+func LoadConfig(filename string, strict bool) (*Config, error) {
+	// Read file contents and decode JSON into config struct
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
+	if data, err = resolveSecretFiles(data); err != nil {
+		return nil, err
+	}
 
 	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(&config); err != nil {
+		if strict && strings.HasPrefix(err.Error(), "json: unknown field") {
+			return nil, fmt.Errorf("%w (run with -strict=false to ignore unknown fields)", err)
+		}
 		return nil, err
 	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the configuration object")
+	}
 
 	return &config, nil
 }
 
+// secretFileRef matches a ${file:/path} reference in a config string
+var secretFileRef = regexp.MustCompile(`\$\{file:([^}]+)\}`)
+
+// resolveSecretFiles replaces ${file:/path} references in config strings with the trimmed file contents
+// e.g. mounted Docker or Kubernetes secrets, so credentials stay out of the config file
+// The config is only rewritten when a string value holds a reference, otherwise errors keep pointing into the file as written
+func resolveSecretFiles(data []byte) ([]byte, error) {
+	if !secretFileRef.Match(data) {
+		return data, nil
+	}
+
+	// Substitute in the decoded values, so file contents never need JSON escaping
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the configuration object")
+	}
+	document, resolved, err := resolveSecretValue(document)
+	if err != nil {
+		return nil, err
+	}
+	if !resolved {
+		return data, nil
+	}
+	return json.Marshal(document)
+}
+
+// resolveSecretValue resolves secret file references in a decoded JSON value and everything nested in it
+// Reports whether any reference was found
+func resolveSecretValue(value any) (any, bool, error) {
+	found := false
+	switch typed := value.(type) {
+	case string:
+		if !secretFileRef.MatchString(typed) {
+			return value, false, nil
+		}
+		var readErr error
+		resolved := secretFileRef.ReplaceAllStringFunc(typed, func(ref string) string {
+			path := secretFileRef.FindStringSubmatch(ref)[1]
+			secret, err := os.ReadFile(path)
+			if err != nil {
+				readErr = cmp.Or(readErr, fmt.Errorf("failed to read secret file: %w", err))
+				return ref
+			}
+			return strings.TrimSpace(string(secret))
+		})
+		return resolved, true, readErr
+	case map[string]any:
+		for key, child := range typed {
+			resolved, ok, err := resolveSecretValue(child)
+			if err != nil {
+				return nil, false, err
+			}
+			typed[key], found = resolved, found || ok
+		}
+	case []any:
+		for i, child := range typed {
+			resolved, ok, err := resolveSecretValue(child)
+			if err != nil {
+				return nil, false, err
+			}
+			typed[i], found = resolved, found || ok
+		}
+	}
+	return value, found, nil
+}
+
 // ParseCompoundPattern parses a compound pattern string into a CompoundPattern struct
 // Uses tokenization followed by recursive parsing to handle nested expressions
 func ParseCompoundPattern(pattern string) (*CompoundPattern, error) {
@@ -105,14 +735,14 @@ type Token struct {
 }
 
 // tokenizePattern converts pattern string into tokens with quote handling
-// Processes character-by-character to identify operators, parentheses and quoted strings
+// Operators are only recognized as whole words outside quotes, so "BRAND NEW" or "COLOR" stay text
 func tokenizePattern(pattern string) ([]Token, error) {
 	var tokens []Token
 	i := 0
 
 	// Iterate through each character to build token list
 	for i < len(pattern) {
-		if pattern[i] == ' ' || pattern[i] == '\t' {
+		if isPatternSpace(pattern[i]) {
 			i++
 			continue
 		}
@@ -128,59 +758,46 @@ func tokenizePattern(pattern string) ([]Token, error) {
 			continue
 		}
 
-		if (i+4 <= len(pattern)) && (pattern[i:i+4] == " AND") && (i+4 >= len(pattern) || pattern[i+4] == ' ') {
-			tokens = append(tokens, Token{Type: "AND", Value: "AND"})
-			i += 4
-			continue
-		}
-		if i+3 <= len(pattern) && pattern[i:i+3] == " OR" && (i+3 >= len(pattern) || pattern[i+3] == ' ') {
-			tokens = append(tokens, Token{Type: "OR", Value: "OR"})
-			i += 3
-			continue
-		}
-		if i+4 <= len(pattern) && pattern[i:i+4] == "AND " {
-			tokens = append(tokens, Token{Type: "AND", Value: "AND"})
-			i += 4
-			continue
-		}
-		if i+3 <= len(pattern) && pattern[i:i+3] == "OR " {
-			tokens = append(tokens, Token{Type: "OR", Value: "OR"})
-			i += 3
+		// NOT only counts at the start of a term, "NOT sold out" negates while "DO NOT DISTURB" stays text
+		if operator := operatorAt(pattern, i, "AND", "OR", "NOT"); operator != "" {
+			tokens = append(tokens, Token{Type: operator, Value: operator})
+			i += len(operator)
 			continue
 		}
 
-		// Handle patterns that may contain quoted strings with spaces
+		// Scan a term up to the next parenthesis or AND/OR that stands on its own outside quotes
 		start := i
-		inQuotes := false
 		quoteChar := byte(0) // Track which quote character we're using
 
 		for i < len(pattern) {
 			char := pattern[i]
 
-			// Track quote state to properly handle quoted content
-			if (char == '"' || char == '\'') && quoteChar == 0 {
-				inQuotes = true
-				quoteChar = char
+			if quoteChar != 0 {
+				if char == quoteChar {
+					quoteChar = 0
+				}
 				i++
 				continue
-			} else if char == quoteChar && inQuotes {
-				inQuotes = false
-				quoteChar = 0
+			}
+
+			// Quotes open at the start of the term or its value, so apostrophes as in "don't" stay text
+			if (char == '"' || char == '\'') && (i == start || pattern[i-1] == ':') {
+				quoteChar = char
 				i++
 				continue
 			}
 
-			// Outside quotes, stop parsing when encountering operators or parentheses
-			if !inQuotes {
-				if char == '(' || char == ')' {
-					break
-				}
+			if char == '(' || char == ')' {
+				break
+			}
 
-				// Check upcoming characters for AND/OR operators
-				if i+4 <= len(pattern) && (pattern[i:i+4] == " AND" || pattern[i:i+4] == "AND ") {
-					break
+			// Whitespace followed by an operator word ends the term
+			if isPatternSpace(char) {
+				next := i
+				for next < len(pattern) && isPatternSpace(pattern[next]) {
+					next++
 				}
-				if i+3 <= len(pattern) && (pattern[i:i+3] == " OR" || pattern[i:i+3] == "OR ") {
+				if operatorAt(pattern, next, "AND", "OR") != "" {
 					break
 				}
 			}
@@ -188,6 +805,10 @@ func tokenizePattern(pattern string) ([]Token, error) {
 			i++
 		}
 
+		if quoteChar != 0 {
+			return nil, fmt.Errorf("unterminated %c quote in %q", quoteChar, pattern[start:])
+		}
+
 		value := strings.TrimSpace(pattern[start:i])
 		if value != "" {
 			tokens = append(tokens, Token{Type: "PATTERN", Value: value})
@@ -197,14 +818,41 @@ func tokenizePattern(pattern string) ([]Token, error) {
 	return tokens, nil
 }
 
+// operatorAt returns the operator word starting at i, if it is followed by whitespace, a parenthesis or the end
+func operatorAt(pattern string, i int, operators ...string) string {
+	for _, operator := range operators {
+		end := i + len(operator)
+		if !strings.HasPrefix(pattern[i:], operator) {
+			continue
+		}
+		if end == len(pattern) || isPatternSpace(pattern[end]) || pattern[end] == '(' || pattern[end] == ')' {
+			return operator
+		}
+	}
+	return ""
+}
+
+// isPatternSpace reports whether c separates words in a compound pattern
+func isPatternSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
 // parseTokens parses a slice of tokens into a CompoundPattern
 func parseTokens(tokens []Token) (*CompoundPattern, error) {
 	if len(tokens) == 0 {
 		return nil, fmt.Errorf("no tokens to parse")
 	}
 
-	compound, _, err := parseOrExpression(tokens, 0)
-	return compound, err
+	compound, pos, err := parseOrExpression(tokens, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Anything left over, like a stray closing parenthesis, means the pattern is malformed
+	if pos < len(tokens) {
+		return nil, fmt.Errorf("unexpected %q", tokens[pos].Value)
+	}
+	return compound, nil
 }
 
 // parseOrExpression handles OR operations with lowest precedence
@@ -261,8 +909,8 @@ func parseAndExpression(tokens []Token, pos int) (*CompoundPattern, int, error)
 	return &CompoundPattern{Operator: "AND", Patterns: elements}, newPos, nil
 }
 
-// parsePrimary handles parentheses and individual patterns
-// Processes leaf nodes and parenthesized sub-expressions
+// parsePrimary handles negation, parentheses and individual patterns
+// NOT binds tighter than AND and applies to the single term or parenthesized group after it
 func parsePrimary(tokens []Token, pos int) (*CompoundPattern, int, error) {
 	if pos >= len(tokens) {
 		return nil, pos, fmt.Errorf("unexpected end of input")
@@ -271,6 +919,19 @@ func parsePrimary(tokens []Token, pos int) (*CompoundPattern, int, error) {
 	token := tokens[pos]
 
 	switch token.Type {
+	case "NOT":
+		operand, newPos, err := parsePrimary(tokens, pos+1)
+		if err != nil {
+			return nil, newPos, err
+		}
+
+		compound := &CompoundPattern{
+			Operator: "AND",
+			Patterns: []PatternElement{{Type: "compound", Compound: operand, Negated: true}},
+		}
+
+		return compound, newPos, nil
+
 	case "LPAREN":
 		// Parse expression inside parentheses recursively
 		compound, newPos, err := parseOrExpression(tokens, pos+1)
@@ -315,38 +976,131 @@ func parsePatternElement(pattern string) (PatternElement, error) {
 	colonIndex := strings.Index(pattern, ":")
 	if colonIndex > 0 && colonIndex < len(pattern)-1 {
 		possibleType := strings.ToLower(strings.TrimSpace(pattern[:colonIndex]))
-		if possibleType == "string" || possibleType == "regex" {
+		if possibleType == "string" || possibleType == "regex" || possibleType == "numeric" {
 			patternValue := strings.TrimSpace(pattern[colonIndex+1:])
 			if patternValue == "" {
 				return PatternElement{}, fmt.Errorf("empty pattern value")
 			}
 
-			// Process quoted patterns by removing surrounding quotes
-			if len(patternValue) >= 2 {
-				firstChar := patternValue[0]
-				lastChar := patternValue[len(patternValue)-1]
-				if (firstChar == '"' && lastChar == '"') || (firstChar == '\'' && lastChar == '\'') {
-					// Remove quote characters from pattern value
-					patternValue = patternValue[1 : len(patternValue)-1]
-				}
-			}
+			return PatternElement{Type: possibleType, Pattern: unquotePattern(patternValue)}, nil
+		}
+	}
 
-			return PatternElement{Type: possibleType, Pattern: patternValue}, nil
+	return PatternElement{Type: "string", Pattern: unquotePattern(pattern)}, nil
+}
+
+// unquotePattern removes the quotes surrounding a pattern value, if any
+func unquotePattern(value string) string {
+	if len(value) >= 2 {
+		firstChar := value[0]
+		lastChar := value[len(value)-1]
+		if (firstChar == '"' && lastChar == '"') || (firstChar == '\'' && lastChar == '\'') {
+			return value[1 : len(value)-1]
 		}
 	}
+	return value
+}
+
+// MatchSource is a match tagged with the pattern element that produced it
+type MatchSource struct {
+	Value   string
+	Type    string
+	Pattern string
+}
+
+// String formats the match with its origin, e.g. "$19.99 (from regex:\$[0-9.]+)"
+func (m MatchSource) String() string {
+	return fmt.Sprintf("%s (from %s:%s)", m.Value, m.Type, m.Pattern)
+}
 
-	return PatternElement{Type: "string", Pattern: pattern}, nil
+// MatchDetail is the outcome of one leaf sub-pattern of a compound pattern
+type MatchDetail struct {
+	Pattern string   `json:"pattern" yaml:"pattern" xml:"pattern,attr"`
+	Type    string   `json:"type" yaml:"type" xml:"type,attr"`
+	Negated bool     `json:"negated,omitempty" yaml:"negated,omitempty" xml:"negated,attr,omitempty"` // Sits under a NOT, so matching counts against the pattern
+	Matched bool     `json:"matched" yaml:"matched" xml:"matched,attr"`
+	Matches []string `json:"matches" yaml:"matches" xml:"match"`
+}
+
+// String formats the detail for messages, e.g. "NOT string:sold out: matched"
+func (d MatchDetail) String() string {
+	status := "not matched"
+	if d.Matched {
+		status = "matched"
+	}
+	if d.Negated {
+		return fmt.Sprintf("NOT %s:%s: %s", d.Type, d.Pattern, status)
+	}
+	return fmt.Sprintf("%s:%s: %s", d.Type, d.Pattern, status)
+}
+
+// EvaluateCompoundPatternDetails reports every leaf sub-pattern of a compound pattern in order
+// Shows which branches of e.g. "A AND (B OR C)" matched, regardless of the overall result
+func EvaluateCompoundPatternDetails(compound *CompoundPattern, content string) ([]MatchDetail, error) {
+	var details []MatchDetail
+	if err := collectMatchDetails(compound, content, false, &details); err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+// collectMatchDetails appends the details of each leaf in compound, tracking whether it sits under a NOT
+func collectMatchDetails(compound *CompoundPattern, content string, negated bool, details *[]MatchDetail) error {
+	if compound == nil {
+		return fmt.Errorf("nil compound pattern")
+	}
+
+	for _, element := range compound.Patterns {
+		if element.Type == "compound" {
+			if err := collectMatchDetails(element.Compound, content, negated != element.Negated, details); err != nil {
+				return err
+			}
+			continue
+		}
+
+		found, sources, err := evaluatePatternElement(element, content)
+		if err != nil {
+			return err
+		}
+		detail := MatchDetail{
+			Pattern: element.Pattern,
+			Type:    element.Type,
+			Negated: negated != element.Negated,
+			Matched: found,
+			Matches: []string{},
+		}
+		for _, source := range sources {
+			detail.Matches = append(detail.Matches, source.Value)
+		}
+		*details = append(*details, detail)
+	}
+	return nil
 }
 
 // EvaluateCompoundPattern evaluates a compound pattern against content
 // Applies the parsed boolean expression to web page content
 func EvaluateCompoundPattern(compound *CompoundPattern, content string) (bool, []string, error) {
+	found, sources, err := EvaluateCompoundPatternWithSources(compound, content)
+	if err != nil {
+		return false, nil, err
+	}
+
+	allMatches := make([]string, len(sources))
+	for i, source := range sources {
+		allMatches[i] = source.Value
+	}
+	return found, allMatches, nil
+}
+
+// EvaluateCompoundPatternWithSources evaluates a compound pattern like EvaluateCompoundPattern
+// Each match is tagged with the pattern element that produced it
+func EvaluateCompoundPatternWithSources(compound *CompoundPattern, content string) (bool, []MatchSource, error) {
 	if compound == nil {
 		return false, nil, fmt.Errorf("nil compound pattern")
 	}
 
 	// Store matches from all sub-patterns for result reporting
-	var allMatches []string
+	var allMatches []MatchSource
 	results := make([]bool, len(compound.Patterns))
 
 	// Test each pattern element against content and gather results
@@ -355,6 +1109,11 @@ func EvaluateCompoundPattern(compound *CompoundPattern, content string) (bool, [
 		if err != nil {
 			return false, nil, fmt.Errorf("error evaluating pattern element %d: %w", i+1, err)
 		}
+
+		// A negated element holds when nothing matched, so it has no matches to report
+		if element.Negated {
+			found, matches = !found, nil
+		}
 		results[i] = found
 		allMatches = append(allMatches, matches...)
 	}
@@ -388,31 +1147,505 @@ func EvaluateCompoundPattern(compound *CompoundPattern, content string) (bool, [
 }
 
 // evaluatePatternElement evaluates a single pattern element against content
-func evaluatePatternElement(element PatternElement, content string) (bool, []string, error) {
+func evaluatePatternElement(element PatternElement, content string) (bool, []MatchSource, error) {
 	switch element.Type {
 	case "string":
-		found := strings.Contains(content, element.Pattern)
-		matches := []string{}
+		value, found := element.Pattern, strings.Contains(content, element.Pattern)
+		if element.IgnoreCase {
+			value, found = containsFold(content, element.Pattern)
+		}
+		matches := []MatchSource{}
 		if found {
-			matches = []string{element.Pattern}
+			matches = []MatchSource{{Value: value, Type: element.Type, Pattern: element.Pattern}}
 		}
 		return found, matches, nil
 
 	case "regex":
-		re, err := regexp.Compile(element.Pattern)
+		pattern := element.Pattern
+		if element.IgnoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
 		if err != nil {
 			return false, nil, fmt.Errorf("invalid regex pattern: %w", err)
 		}
-		matches := re.FindAllString(content, -1)
+		var matches []MatchSource
+		for _, match := range re.FindAllString(content, -1) {
+			matches = append(matches, MatchSource{Value: match, Type: element.Type, Pattern: element.Pattern})
+		}
 		return len(matches) > 0, matches, nil
 
+	case "numeric":
+		found, values, err := EvaluateNumericCondition(content, element.Pattern)
+		if err != nil {
+			return false, nil, err
+		}
+		var matches []MatchSource
+		for _, value := range values {
+			matches = append(matches, MatchSource{Value: value, Type: element.Type, Pattern: element.Pattern})
+		}
+		return found, matches, nil
+
 	case "compound":
 		if element.Compound == nil {
 			return false, nil, fmt.Errorf("nil nested compound pattern")
 		}
-		return EvaluateCompoundPattern(element.Compound, content)
+		return EvaluateCompoundPatternWithSources(element.Compound, content)
 
 	default:
 		return false, nil, fmt.Errorf("unsupported pattern element type: %s", element.Type)
 	}
 }
+
+// PerfCondition represents a parsed performance threshold such as "load_time > 3000"
+type PerfCondition struct {
+	Metric    string
+	Operator  string
+	Threshold float64
+}
+
+// ParsePerfPattern parses a "<metric> <operator> <threshold>" performance pattern
+func ParsePerfPattern(pattern string) (*PerfCondition, error) {
+	fields := strings.Fields(pattern)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("expected '<metric> <operator> <threshold>', got %q", pattern)
+	}
+
+	if _, ok := (&PerformanceMetrics{}).Metric(fields[0]); !ok {
+		return nil, fmt.Errorf("unknown performance metric: %s", fields[0])
+	}
+
+	if _, err := compareValues(0, fields[1], 0); err != nil {
+		return nil, err
+	}
+
+	threshold, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold %q: %w", fields[2], err)
+	}
+
+	return &PerfCondition{Metric: fields[0], Operator: fields[1], Threshold: threshold}, nil
+}
+
+// EvaluatePerfCondition checks collected performance metrics against a perf pattern
+// Reports the measured value as the match when the condition holds
+func EvaluatePerfCondition(metrics *PerformanceMetrics, pattern string) (bool, []string, error) {
+	if metrics == nil {
+		return false, nil, fmt.Errorf("no performance metrics available")
+	}
+
+	condition, err := ParsePerfPattern(pattern)
+	if err != nil {
+		return false, nil, err
+	}
+
+	value, _ := metrics.Metric(condition.Metric)
+	found, err := compareValues(value, condition.Operator, condition.Threshold)
+	if err != nil {
+		return false, nil, err
+	}
+
+	matches := []string{}
+	if found {
+		matches = []string{fmt.Sprintf("%s=%.0fms", condition.Metric, value)}
+	}
+	return found, matches, nil
+}
+
+// compareValues applies a numeric comparison operator to a value and threshold
+func compareValues(value float64, operator string, threshold float64) (bool, error) {
+	switch operator {
+	case "<":
+		return value < threshold, nil
+	case "<=":
+		return value <= threshold, nil
+	case ">":
+		return value > threshold, nil
+	case ">=":
+		return value >= threshold, nil
+	case "==":
+		return value == threshold, nil
+	case "!=":
+		return value != threshold, nil
+	default:
+		return false, fmt.Errorf("unsupported comparison operator: %s", operator)
+	}
+}
+
+// NotifyPredicate is a parsed notify_if expression where all terms must hold
+type NotifyPredicate struct {
+	Terms []PredicateTerm
+}
+
+// PredicateTerm is a single "changed" or "value <operator> <number|previous>" condition
+type PredicateTerm struct {
+	Changed   bool
+	Operator  string
+	Threshold float64
+	Previous  bool // Compare against the previous value instead of Threshold
+}
+
+// ParseNotifyPredicate parses a notify_if expression of terms joined by AND
+func ParseNotifyPredicate(expr string) (*NotifyPredicate, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("empty predicate")
+	}
+
+	var predicate NotifyPredicate
+	for _, part := range strings.Split(expr, " AND ") {
+		fields := strings.Fields(part)
+
+		if len(fields) == 1 && fields[0] == "changed" {
+			predicate.Terms = append(predicate.Terms, PredicateTerm{Changed: true})
+			continue
+		}
+
+		if len(fields) != 3 || fields[0] != "value" {
+			return nil, fmt.Errorf("invalid term %q, expected 'changed' or 'value <operator> <number|previous>'", part)
+		}
+
+		if _, err := compareValues(0, fields[1], 0); err != nil {
+			return nil, err
+		}
+
+		term := PredicateTerm{Operator: fields[1]}
+		if fields[2] == "previous" {
+			term.Previous = true
+		} else {
+			threshold, err := strconv.ParseFloat(fields[2], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid threshold %q: %w", fields[2], err)
+			}
+			term.Threshold = threshold
+		}
+		predicate.Terms = append(predicate.Terms, term)
+	}
+
+	return &predicate, nil
+}
+
+// Evaluate checks the predicate against the current match value and the previous one, if any
+// Numeric terms fail when a value can't be parsed as a number
+func (p *NotifyPredicate) Evaluate(current string, previous *string) bool {
+	for _, term := range p.Terms {
+		if term.Changed {
+			if previous != nil && *previous == current {
+				return false
+			}
+			continue
+		}
+
+		value, err := extractNumber(current)
+		if err != nil {
+			return false
+		}
+
+		threshold := term.Threshold
+		if term.Previous {
+			if previous == nil {
+				return false
+			}
+			if threshold, err = extractNumber(*previous); err != nil {
+				return false
+			}
+		}
+
+		if holds, _ := compareValues(value, term.Operator, threshold); !holds {
+			return false
+		}
+	}
+	return true
+}
+
+// numberPattern matches the first number in text including any , . or ' separators between its digits
+var numberPattern = regexp.MustCompile(`-?\d+(?:[,.']\d+)*`)
+
+// separatorPattern matches the separators inside a number
+var separatorPattern = regexp.MustCompile(`[,.']`)
+
+// extractNumber parses the first number in text, ignoring currency symbols and thousands separators
+func extractNumber(text string) (float64, error) {
+	_, value, err := findNumber(text)
+	return value, err
+}
+
+// findNumber returns the first number in text as written and its value
+// Handles both "1,299.99" and "1.299,99", a lone dot is always a decimal point
+func findNumber(text string) (string, float64, error) {
+	written := numberPattern.FindString(text)
+	if written == "" {
+		return "", 0, fmt.Errorf("no number found in %q", text)
+	}
+
+	groups := separatorPattern.Split(written, -1)
+	separators := separatorPattern.FindAllString(written, -1)
+	decimal, ok := decimalSeparator(groups, separators)
+	if !ok {
+		// Separators that don't form one number, as in "1.2.3", end it after the first decimal part
+		written, decimal = groups[0], -1
+		if separators[0] != "'" {
+			written, decimal = written+separators[0]+groups[1], 0
+		}
+		groups = groups[:decimal+2]
+	}
+
+	number := strings.Join(groups, "")
+	if decimal >= 0 {
+		number = strings.Join(groups[:decimal+1], "") + "." + groups[decimal+1]
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	return written, value, err
+}
+
+// decimalSeparator returns the position of the decimal separator among a number's separators, -1 without one
+// The last separator is the decimal one when it differs from the others or is a lone dot, the rest separate
+// thousands. A comma before groups of three digits separates thousands unless the number starts with 0,
+// so "1,299" is 1299 while "19,99" and "0,500" are decimals
+func decimalSeparator(groups, separators []string) (int, bool) {
+	if len(separators) == 0 {
+		return -1, true
+	}
+
+	last := separators[len(separators)-1]
+	thousands := separators[:len(separators)-1]
+	for _, separator := range thousands {
+		if separator != thousands[0] {
+			return 0, false
+		}
+	}
+
+	switch {
+	case len(thousands) > 0 && thousands[0] != last:
+		// Mixed separators like "1,299.99", only . and , can be the decimal one
+		return len(thousands), last != "'" && thousandsGroups(groups[:len(groups)-1])
+	case last == "'" || (last == "." && len(thousands) > 0):
+		return -1, thousandsGroups(groups)
+	case last == ",":
+		if thousandsGroups(groups) {
+			return -1, true
+		}
+		return 0, len(thousands) == 0
+	default:
+		return 0, true
+	}
+}
+
+// thousandsGroups reports whether digit groups are written in thousands, like 1 299 or 12 345 678
+func thousandsGroups(groups []string) bool {
+	leading := strings.TrimPrefix(groups[0], "-")
+	if len(leading) > 3 || leading[0] == '0' {
+		return false
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// NumericCondition is a parsed numeric search pattern such as "< 500"
+type NumericCondition struct {
+	Operator  string
+	Threshold float64
+}
+
+// numericPatternRegex splits a numeric pattern into operator and threshold, spaces between them are optional
+var numericPatternRegex = regexp.MustCompile(`^(<=|>=|==|!=|<|>)\s*(.+)$`)
+
+// ParseNumericPattern parses a numeric pattern of the form "<operator> <threshold>"
+// The threshold may be written like a price, e.g. ">= 1,299.99"
+func ParseNumericPattern(pattern string) (*NumericCondition, error) {
+	parts := numericPatternRegex.FindStringSubmatch(strings.TrimSpace(pattern))
+	if parts == nil {
+		return nil, fmt.Errorf("expected '<operator> <threshold>' with operator <, <=, >, >=, == or !=, got %q", pattern)
+	}
+
+	written, threshold, err := findNumber(parts[2])
+	if err != nil || strings.TrimSpace(parts[2]) != written {
+		return nil, fmt.Errorf("invalid threshold %q", parts[2])
+	}
+
+	return &NumericCondition{Operator: parts[1], Threshold: threshold}, nil
+}
+
+// EvaluateNumericCondition compares the first number in content against a numeric pattern
+// Reports the number as written on the page when the condition holds
+func EvaluateNumericCondition(content string, pattern string) (bool, []string, error) {
+	condition, err := ParseNumericPattern(pattern)
+	if err != nil {
+		return false, nil, err
+	}
+
+	written, value, err := findNumber(content)
+	if err != nil {
+		return false, nil, err
+	}
+
+	found, err := compareValues(value, condition.Operator, condition.Threshold)
+	if err != nil {
+		return false, nil, err
+	}
+
+	matches := []string{}
+	if found {
+		matches = []string{written}
+	}
+	return found, matches, nil
+}
+
+// sortMatches orders matches in place as configured by sort_matches
+// Numeric sorting compares the first number in each match, matches without one go last
+func sortMatches(matches []string, order string) {
+	switch order {
+	case "asc":
+		slices.Sort(matches)
+	case "desc":
+		slices.Sort(matches)
+		slices.Reverse(matches)
+	case "numeric":
+		slices.SortStableFunc(matches, func(a, b string) int {
+			numberA, errA := extractNumber(a)
+			numberB, errB := extractNumber(b)
+			switch {
+			case errA != nil && errB != nil:
+				return 0
+			case errA != nil:
+				return 1
+			case errB != nil:
+				return -1
+			}
+			return cmp.Compare(numberA, numberB)
+		})
+	}
+}
+
+// selectMatches keeps only the match chosen by match_select, after sorting
+// min and max compare the first number in each match and ignore matches without one
+func selectMatches(matches []string, mode string) []string {
+	if len(matches) == 0 {
+		return matches
+	}
+
+	switch mode {
+	case "first":
+		return matches[:1]
+	case "last":
+		return matches[len(matches)-1:]
+	case "longest", "shortest":
+		chosen := matches[0]
+		for _, match := range matches[1:] {
+			longer := utf8.RuneCountInString(match) > utf8.RuneCountInString(chosen)
+			shorter := utf8.RuneCountInString(match) < utf8.RuneCountInString(chosen)
+			if (mode == "longest" && longer) || (mode == "shortest" && shorter) {
+				chosen = match
+			}
+		}
+		return []string{chosen}
+	case "min", "max":
+		chosen, found := "", false
+		var chosenNumber float64
+		for _, match := range matches {
+			number, err := extractNumber(match)
+			if err != nil {
+				continue
+			}
+			if !found || (mode == "min" && number < chosenNumber) || (mode == "max" && number > chosenNumber) {
+				chosen, chosenNumber, found = match, number, true
+			}
+		}
+		if !found {
+			return matches
+		}
+		return []string{chosen}
+	default:
+		return matches
+	}
+}
+
+// DateCondition represents a parsed date comparison such as "older_than 7d"
+type DateCondition struct {
+	Operator string        // "older_than", "newer_than" or "changed"
+	MaxAge   time.Duration // Age limit for older_than
+}
+
+// ParseDatePattern parses "older_than <age>", "newer_than" or "changed"
+// Ages accept Go durations plus a "d" suffix for days
+func ParseDatePattern(pattern string) (*DateCondition, error) {
+	fields := strings.Fields(pattern)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty date pattern")
+	}
+
+	switch fields[0] {
+	case "older_than":
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("expected 'older_than <age>', got %q", pattern)
+		}
+		maxAge, err := parseAge(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		return &DateCondition{Operator: "older_than", MaxAge: maxAge}, nil
+	case "newer_than", "changed":
+		if len(fields) != 1 {
+			return nil, fmt.Errorf("%s takes no arguments", fields[0])
+		}
+		return &DateCondition{Operator: fields[0]}, nil
+	default:
+		return nil, fmt.Errorf("unsupported date comparison: %s", fields[0])
+	}
+}
+
+// parseAge parses a duration, allowing whole days such as "7d"
+func parseAge(age string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(age, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q: %w", age, err)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	duration, err := time.ParseDuration(age)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q: %w", age, err)
+	}
+	return duration, nil
+}
+
+// parseContentDate parses the extracted content, or failing that its first parseable line, as a date
+func parseContentDate(content, layout string) (time.Time, error) {
+	if date, err := time.Parse(layout, strings.TrimSpace(content)); err == nil {
+		return date, nil
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		if date, err := time.Parse(layout, strings.TrimSpace(line)); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("no date matching layout %q found", layout)
+}
+
+// EvaluateDateCondition compares the date found in content against the condition
+// newer_than and changed compare with lastSeen and never hold on the first observation
+func EvaluateDateCondition(content, layout string, condition *DateCondition, lastSeen *time.Time) (bool, time.Time, error) {
+	date, err := parseContentDate(content, layout)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+
+	switch condition.Operator {
+	case "older_than":
+		return time.Since(date) > condition.MaxAge, date, nil
+	case "newer_than":
+		return lastSeen != nil && date.After(*lastSeen), date, nil
+	case "changed":
+		return lastSeen != nil && !date.Equal(*lastSeen), date, nil
+	default:
+		return false, date, fmt.Errorf("unsupported date comparison: %s", condition.Operator)
+	}
+}
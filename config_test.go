@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindNumber(t *testing.T) {
+	tests := []struct {
+		text    string
+		written string
+		value   float64
+	}{
+		{"42", "42", 42},
+		{"0.500", "0.500", 0.5},
+		{"€1.899/l", "1.899", 1.899},
+		{"1.500", "1.500", 1.5},
+		{"19.99 €", "19.99", 19.99},
+		{"19,99 €", "19,99", 19.99},
+		{"0,500", "0,500", 0.5},
+		{"$1,299", "1,299", 1299},
+		{"1,299,999", "1,299,999", 1299999},
+		{"1,299.99", "1,299.99", 1299.99},
+		{"1.299,99 €", "1.299,99", 1299.99},
+		{"1.299.999", "1.299.999", 1299999},
+		{"CHF 1'299.50", "1'299.50", 1299.5},
+		{"-1,299.99", "-1,299.99", -1299.99},
+		{"version 1.2.3", "1.2", 1.2},
+		{"Items 1, 2, 3", "1", 1},
+		{"Price: 12.99. Shipping free", "12.99", 12.99},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			written, value, err := findNumber(test.text)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if written != test.written || value != test.value {
+				t.Errorf("findNumber(%q) = %q, %v, want %q, %v", test.text, written, value, test.written, test.value)
+			}
+		})
+	}
+}
+
+func TestFindNumberWithoutNumber(t *testing.T) {
+	if _, _, err := findNumber("sold out"); err == nil {
+		t.Error("expected an error for text without a number")
+	}
+}
+
+func TestParseNumericPatternThreshold(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		operator  string
+		threshold float64
+	}{
+		{"< 0.500", "<", 0.5},
+		{">= 19.99", ">=", 19.99},
+		{"<1,299", "<", 1299},
+		{"<= 1.299,99", "<=", 1299.99},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			condition, err := ParseNumericPattern(test.pattern)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if condition.Operator != test.operator || condition.Threshold != test.threshold {
+				t.Errorf("ParseNumericPattern(%q) = %s %v, want %s %v", test.pattern, condition.Operator, condition.Threshold, test.operator, test.threshold)
+			}
+		})
+	}
+
+	if _, err := ParseNumericPattern("< 1.2.3"); err == nil {
+		t.Error("expected an error for an invalid threshold")
+	}
+}
+
+func TestLoadConfigSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "token")
+	if err := os.WriteFile(secret, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig := func(content string) string {
+		path := filepath.Join(dir, "config.json")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	config, err := LoadConfig(writeConfig(`{"url": "https://example.com", "proxy": "${file:`+secret+`}"}`), true)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if config.Proxy != "s3cret" {
+		t.Errorf("proxy = %q, want the trimmed secret file content", config.Proxy)
+	}
+
+	_, err = LoadConfig(writeConfig(`{"proxy": "${file:`+secret+`}"} {"url": "https://example.com"}`), true)
+	if err == nil || !strings.Contains(err.Error(), "unexpected data after the configuration object") {
+		t.Errorf("trailing data with a secret reference: got %v", err)
+	}
+}
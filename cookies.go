@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// parseCookiesFile reads cookies from a Netscape cookies.txt file as exported by browser extensions and curl
+// Lines are domain, include subdomains, path, secure, expiry, name and value separated by tabs
+func parseCookiesFile(path string) ([]CookieConfig, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cookies file: %w", err)
+	}
+	defer file.Close()
+
+	var cookies []CookieConfig
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		// HttpOnly cookies are written as comments with a marker prefix
+		line = strings.TrimPrefix(line, "#HttpOnly_")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("cookies file %s line %d: expected 7 tab separated fields, got %d", path, lineNumber, len(fields))
+		}
+		cookies = append(cookies, CookieConfig{
+			Domain: fields[0],
+			Path:   fields[2],
+			Secure: strings.EqualFold(fields[3], "TRUE"),
+			Name:   fields[5],
+			Value:  fields[6],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cookies file: %w", err)
+	}
+	return cookies, nil
+}
+
+// newCookieJar creates a jar holding the configured cookies for the http fetch mode
+func newCookieJar(cookies []CookieConfig) (http.CookieJar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cookie := range cookies {
+		// The jar files cookies under the URL they were received from
+		cookieURL := &url.URL{Scheme: "https", Host: strings.TrimPrefix(cookie.Domain, "."), Path: cookie.Path}
+		jar.SetCookies(cookieURL, []*http.Cookie{{
+			Name:   cookie.Name,
+			Value:  cookie.Value,
+			Domain: cookie.Domain,
+			Path:   cookie.Path,
+			Secure: cookie.Secure,
+		}})
+	}
+	return jar, nil
+}
+
+// browserCookies converts the configured cookies for the browser's cookie store
+func browserCookies(cookies []CookieConfig) []*proto.NetworkCookieParam {
+	params := make([]*proto.NetworkCookieParam, 0, len(cookies))
+	for _, cookie := range cookies {
+		params = append(params, &proto.NetworkCookieParam{
+			Name:   cookie.Name,
+			Value:  cookie.Value,
+			Domain: cookie.Domain,
+			Path:   cookie.Path,
+			Secure: cookie.Secure,
+		})
+	}
+	return params
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// GitHubClient checks a repository's latest release or tag through the GitHub API
+// Keeps the last seen version in the state file so only new versions are reported as found, also across restarts
+type GitHubClient struct {
+	client *http.Client
+}
+
+// GitHubTag represents the fields used from the GitHub tags API
+type GitHubTag struct {
+	Name string `json:"name"`
+}
+
+// NewGitHubClient creates a new GitHub API client bounded by the configured timeout
+func NewGitHubClient(config *Config) *GitHubClient {
+	return &GitHubClient{
+		client: &http.Client{Timeout: time.Duration(config.Timeout) * time.Second},
+	}
+}
+
+// Close releases the client resources
+func (g *GitHubClient) Close() {}
+
+// Fetch implements the Client interface for GitHub release monitoring
+// The first check establishes a baseline, later checks find versions that differ from it
+func (g *GitHubClient) Fetch(config *Config) *Result {
+	githubConfig := config.GitHub
+
+	// Every API request may take up to the target's timeout
+	client := *g.client
+	client.Timeout = time.Duration(config.Timeout) * time.Second
+
+	var version string
+	var err error
+	if githubConfig.Source == "tag" {
+		version, err = latestTag(&client, githubConfig)
+	} else {
+		var release *GitHubRelease
+		release, err = fetchLatestRelease(&client, githubConfig.Repo, githubConfig.Token)
+		if release != nil {
+			version = release.TagName
+		}
+	}
+	if err != nil {
+		return &Result{Error: err}
+	}
+
+	store, err := loadStateStore(config.StateFile)
+	if err != nil {
+		return &Result{Error: err}
+	}
+	key := fmt.Sprintf("github|%s|%s|%s", config.Name, githubConfig.Repo, githubConfig.Source)
+	previous, seen, err := store.swap(key, version)
+	if err != nil {
+		return &Result{Error: err}
+	}
+
+	result := &Result{Content: version, Found: seen && previous != version}
+	if result.Found {
+		result.Matches = []string{fmt.Sprintf("%s (previously %s)", version, previous)}
+	}
+	return result
+}
+
+// latestTag returns the most recent tag of the configured repository
+func latestTag(client *http.Client, githubConfig *GitHubConfig) (string, error) {
+	var tags []GitHubTag
+	url := fmt.Sprintf("https://api.github.com/repos/%s/tags?per_page=1", githubConfig.Repo)
+	if err := getGitHubJSON(client, url, githubConfig.Token, &tags); err != nil {
+		return "", err
+	}
+
+	if len(tags) == 0 {
+		return "", fmt.Errorf("repository %s has no tags", githubConfig.Repo)
+	}
+	return tags[0].Name, nil
+}
+
+// fetchLatestRelease returns the latest published release of a repository
+func fetchLatestRelease(client *http.Client, repo, token string) (*GitHubRelease, error) {
+	var release GitHubRelease
+	url := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", repo)
+	if err := getGitHubJSON(client, url, token, &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// getGitHubJSON performs an authenticated GitHub API request and decodes the JSON response
+// The token is optional and only raises the rate limit
+func getGitHubJSON(client *http.Client, url, token string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("github API returned status %d for %s", resp.StatusCode, url)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode github response: %w", err)
+	}
+	return nil
+}
@@ -2,12 +2,23 @@ module uptodate
 
 go 1.24.3
 
-require github.com/go-rod/rod v0.116.2
+require (
+	github.com/andybalholm/cascadia v1.3.3
+	github.com/antchfx/htmlquery v1.3.5
+	github.com/go-rod/rod v0.116.2
+	golang.org/x/crypto v0.45.0
+	golang.org/x/net v0.47.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
+	github.com/antchfx/xpath v1.3.5 // indirect
+	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
 	github.com/ysmood/fetchup v0.2.3 // indirect
 	github.com/ysmood/goob v0.4.0 // indirect
 	github.com/ysmood/got v0.40.0 // indirect
 	github.com/ysmood/gson v0.7.3 // indirect
 	github.com/ysmood/leakless v0.9.0 // indirect
+	golang.org/x/sys v0.38.0 // indirect
+	golang.org/x/text v0.31.0 // indirect
 )
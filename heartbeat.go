@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// heartbeat periodically reports that the monitor is still running, whatever the pattern state
+// A missing heartbeat is the sign that the process died or can't reach its channels
+type heartbeat struct {
+	service  *NotificationService
+	interval time.Duration
+
+	lastOK    map[string]time.Time // Last successful check per monitor label
+	lastError map[string]string    // Error of the last check per monitor label, cleared by a success
+}
+
+// newHeartbeat creates the heartbeat for the configured channels, nil when no heartbeat is configured
+// State of the heartbeat it replaces after a config reload carries on
+func newHeartbeat(monitors []*Config, previous *heartbeat, dryRun bool) *heartbeat {
+	heartbeatConfig := monitors[0].Notifications.Heartbeat
+	if heartbeatConfig == nil {
+		return nil
+	}
+
+	// The heartbeat is about the whole process, not one page or pattern
+	config := *monitors[0]
+	config.Name, config.URL = "", ""
+	config.SearchConfig = SearchConfig{}
+	config.Notifications = config.Notifications.only(heartbeatConfig.Channels)
+	config.Notifications.Escalation = nil
+
+	h := &heartbeat{
+		service:   NewNotificationService(&config),
+		interval:  time.Duration(heartbeatConfig.IntervalHours) * time.Hour,
+		lastOK:    make(map[string]time.Time),
+		lastError: make(map[string]string),
+	}
+	h.service.SetDryRun(dryRun)
+	if previous != nil {
+		h.lastOK, h.lastError = previous.lastOK, previous.lastError
+	}
+	return h
+}
+
+// record remembers the outcome of a monitor's check for the next heartbeat
+func (h *heartbeat) record(config *Config, result *Result) {
+	if result.Error != nil {
+		h.lastError[config.Label()] = result.Error.Error()
+		return
+	}
+	h.lastOK[config.Label()] = time.Now()
+	delete(h.lastError, config.Label())
+}
+
+// send notifies the heartbeat channels with the last check of every monitor
+func (h *heartbeat) send(monitors []*Config, paused bool) {
+	var lines []string
+	if paused {
+		lines = append(lines, "Monitoring is paused")
+	}
+	for _, monitor := range monitors {
+		label := monitor.Label()
+		status := "no successful check yet"
+		if lastOK, ok := h.lastOK[label]; ok {
+			status = "last check OK at " + lastOK.Format(defaultTimeFormat)
+		}
+		if lastError, ok := h.lastError[label]; ok {
+			status += fmt.Sprintf(", last check failed: %s", lastError)
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", label, status))
+	}
+
+	if err := h.service.SendHeartbeat(lines); err != nil {
+		log.Printf("Heartbeat error: %v", err)
+	}
+}
@@ -0,0 +1,575 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// maxMetaRefreshes bounds how many meta refresh pages are followed in a row, like the client's redirect limit
+const maxMetaRefreshes = 10
+
+// metaRefreshURL reads the target from a refresh content value such as "0; url=/page"
+var metaRefreshURL = regexp.MustCompile(`(?i)^\s*[\d.]*\s*[;,]\s*(?:url\s*=\s*)?(.+)$`)
+
+// httpUserAgent is sent with every request, many sites reject Go's default user agent
+const httpUserAgent = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+
+// HTTP handles web operations using a plain HTTP client
+// Much lighter than the browser for static pages, but runs no JavaScript
+type HTTP struct {
+	client *http.Client
+	*searchState
+}
+
+// NewHTTP creates a new HTTP client instance
+func NewHTTP(config *Config) (*HTTP, error) {
+	// Honor the standard proxy environment variables unless disabled
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	if config.UseEnvProxy == nil || *config.UseEnvProxy {
+		transport.Proxy = http.ProxyFromEnvironment
+	}
+
+	// A configured proxy wins over the environment, credentials in its URL are sent to it
+	if config.Proxy != "" {
+		proxyURL, err := url.Parse(config.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL")
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	// Enforce the configured TLS policy
+	tlsConfig, err := newTLSConfig(config.TLS)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	// Attach configured cookies such as a login session to every request
+	jar, err := newCookieJar(config.Cookies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+
+	return &HTTP{
+		client:      &http.Client{Transport: transport, Jar: jar},
+		searchState: newSearchState(),
+	}, nil
+}
+
+// describeProxy reports which proxy requests to a URL go through, for the startup log
+func (h *HTTP) describeProxy(rawURL string) string {
+	proxy := h.client.Transport.(*http.Transport).Proxy
+	if proxy == nil {
+		return "disabled"
+	}
+
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "unknown"
+	}
+	proxyURL, err := proxy(req)
+	if err != nil {
+		return fmt.Sprintf("invalid (%v)", err)
+	}
+	if proxyURL == nil {
+		return "none"
+	}
+	// The transport hands out the same URL on every request, so only a copy loses the credentials
+	redacted := *proxyURL
+	redacted.User = nil // Keep credentials out of the log
+	return redacted.String()
+}
+
+// Close releases idle connections
+func (h *HTTP) Close() {
+	h.client.CloseIdleConnections()
+}
+
+// Fetch implements the Client interface for HTTP-based fetching
+// Downloads the page, extracts content from the parsed HTML or JSON, and searches for patterns
+func (h *HTTP) Fetch(config *Config) *Result {
+	body, pageURL, response, err := h.get(config, config.URL)
+
+	// Interstitial pages that forward with a meta refresh are followed like a redirect, as the browser does
+	for refreshes := 0; err == nil && config.FollowMetaRefresh; refreshes++ {
+		target := metaRefreshTarget(body, pageURL, response.Header.Get("Content-Type"))
+		if target == "" {
+			break
+		}
+		if refreshes == maxMetaRefreshes {
+			err = fmt.Errorf("stopped after %d meta refreshes", maxMetaRefreshes)
+			break
+		}
+		debugf("%sFollowing meta refresh to %s", logPrefix(config), target)
+		elapsed := response.Duration
+		body, pageURL, response, err = h.get(config, target)
+		if response != nil {
+			response.Duration += elapsed
+		}
+	}
+	if err != nil {
+		return &Result{Error: err, Response: response}
+	}
+
+	result := h.searchBody(config, body, pageURL, response.Header.Get("Content-Type"))
+	result.Response = response
+	return result
+}
+
+// searchBody extracts content from a downloaded body and searches it
+func (h *HTTP) searchBody(config *Config, body string, pageURL *url.URL, contentType string) *Result {
+	var content string
+	var err error
+
+	// JSON APIs are searched as JSON, parsing them as HTML would mangle markup inside strings
+	if config.SearchConfig.JSONPath != "" {
+		if content, err = extractWithJSONPath(body, &config.SearchConfig); err != nil {
+			return &Result{Error: err}
+		}
+		return h.search(config, &pageData{content: content})
+	}
+	// Without a jsonpath, a JSON response is searched as is unless the search needs the HTML structure
+	if isJSONContentType(contentType) && !config.SearchConfig.needsHTML() {
+		return h.search(config, &pageData{content: body})
+	}
+
+	doc, err := htmlquery.Parse(strings.NewReader(body))
+	if err != nil {
+		return &Result{
+			Error: fmt.Errorf("failed to parse HTML: %w", err),
+		}
+	}
+
+	// Named checks each extract and search their own region of the page
+	if len(config.SearchConfig.Checks) > 0 {
+		return h.runChecks(config, func(xpath string) (*string, error) {
+			return checkNodeContent(doc, xpath)
+		})
+	}
+
+	// Relations compare the values of two elements
+	if strings.ToLower(config.SearchConfig.Type) == "relation" {
+		return runRelation(config, func(xpath string) (*string, error) {
+			return checkNodeContent(doc, xpath)
+		})
+	}
+
+	// Extract content with a regex over the raw source, an XPath or CSS selector or the entire page body
+	if config.SearchConfig.ExtractRegex != "" {
+		if content, err = extractWithRegex(body, &config.SearchConfig); err != nil {
+			return &Result{Error: err}
+		}
+	} else if config.SearchConfig.selector() != "" {
+		nodes, err := queryNodes(doc, &config.SearchConfig)
+		if err != nil {
+			return &Result{
+				Error: fmt.Errorf("failed to find elements for %s: %w", config.SearchConfig.describeSelector(), err),
+			}
+		}
+
+		// Element searches only care whether the selector matches, not what it contains
+		if strings.ToLower(config.SearchConfig.Type) == "element" {
+			result := &Result{Found: len(nodes) > 0}
+			if result.Found {
+				result.Content = extractText(nodes[0])
+				result.Matches = []string{fmt.Sprintf("%d matching element(s)", len(nodes))}
+			}
+			return result
+		}
+
+		if len(nodes) > 0 {
+			if content, err = selectedNodesContent(nodes, &config.SearchConfig); err != nil {
+				return &Result{Error: err}
+			}
+		} else {
+			// Decide how a selector that matches nothing is handled
+			switch config.SearchConfig.OnEmptyExtraction {
+			case "fallback-body":
+				content = extractText(bodyNode(doc))
+			case "empty":
+				content = ""
+			default:
+				return &Result{
+					Error: fmt.Errorf("%s matched no elements", config.SearchConfig.describeSelector()),
+				}
+			}
+		}
+	} else {
+		content = extractText(bodyNode(doc))
+	}
+
+	data := &pageData{content: content}
+
+	// Read schema.org availability for availability searches
+	if strings.ToLower(config.SearchConfig.Type) == "availability" {
+		data.availability = structuredAvailability(doc)
+	}
+
+	// Collect link or image URLs for links searches
+	if strings.ToLower(config.SearchConfig.Type) == "links" {
+		data.links = collectLinks(doc, pageURL, config.SearchConfig.LinkSource)
+	}
+
+	return h.search(config, data)
+}
+
+// search runs the named searches or the configured pattern on the extracted page data
+func (h *HTTP) search(config *Config, data *pageData) *Result {
+	content := data.content
+
+	// Named searches each run their own pattern on the extracted text
+	if len(config.SearchConfig.Searches) > 0 {
+		return h.runSearches(config, data)
+	}
+
+	// Search the extracted text using configured pattern type
+	found, matches, err := h.performSearch(config.stateKey(), data, &config.SearchConfig)
+	if err != nil {
+		return &Result{
+			Content: content,
+			Error:   fmt.Errorf("search failed: %w", err),
+		}
+	}
+
+	return &Result{
+		Found:    found,
+		Content:  content,
+		Matches:  matches,
+		Patterns: compoundDetails(&config.SearchConfig, content),
+	}
+}
+
+// get downloads a URL of the target, retrying DNS failures with backoff
+// Returns the body, the final URL after redirects and the response metadata, which is also set for HTTP errors
+func (h *HTTP) get(config *Config, rawURL string) (string, *url.URL, *ResponseInfo, error) {
+	start := time.Now()
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", httpUserAgent)
+	for name, value := range config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := h.do(req, config)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	// Rate limited and unavailable responses are retried once after the delay the server asks for
+	if delay, ok := retryAfter(resp, time.Now()); ok && delay <= time.Duration(config.MaxRetryAfter)*time.Second {
+		resp.Body.Close()
+		log.Printf("%sHTTP %d, retrying in %v as asked by Retry-After", logPrefix(config), resp.StatusCode, delay)
+		time.Sleep(delay)
+		if resp, err = h.do(req, config); err != nil {
+			return "", nil, nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	response := &ResponseInfo{
+		StatusCode: resp.StatusCode,
+		URL:        resp.Request.URL.String(),
+		Header:     resp.Header,
+		Duration:   float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	// 429 is a rate limit rather than a client error, so retry may try again later
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return "", nil, response, &TransientError{Err: statusError(resp)}
+	}
+	if resp.StatusCode >= 400 {
+		return "", nil, response, statusError(resp)
+	}
+
+	// Include reading the body, which is most of the time for large pages
+	// Reading stops one byte past the cap, so a huge or endless response can't exhaust memory
+	limit := int64(config.MaxBodySize) << 20
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	response.Duration = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		return "", nil, response, fmt.Errorf("failed to read response: %w", err)
+	}
+	if int64(len(body)) > limit {
+		return "", nil, response, fmt.Errorf("response body exceeds max_body_size of %d MB", config.MaxBodySize)
+	}
+	return string(body), resp.Request.URL, response, nil
+}
+
+// metaRefreshTarget returns the absolute URL an HTML page forwards to with a meta refresh, empty without one
+// A refresh that only reloads the page itself is not followed
+func metaRefreshTarget(body string, pageURL *url.URL, contentType string) string {
+	if isJSONContentType(contentType) {
+		return ""
+	}
+	doc, err := htmlquery.Parse(strings.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	meta := htmlquery.FindOne(doc, "//meta[translate(@http-equiv, 'REFSH', 'refsh')='refresh']")
+	if meta == nil {
+		return ""
+	}
+
+	match := metaRefreshURL.FindStringSubmatch(htmlquery.SelectAttr(meta, "content"))
+	if match == nil {
+		return ""
+	}
+	target, err := pageURL.Parse(strings.Trim(strings.TrimSpace(match[1]), `'"`))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return ""
+	}
+	target.Fragment = ""
+	if target.String() == pageURL.String() {
+		return ""
+	}
+	return target.String()
+}
+
+// isJSONContentType reports whether a Content-Type header denotes JSON, e.g. application/json or application/ld+json
+func isJSONContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// do sends a request, retrying DNS failures with exponential backoff
+// Every attempt may take up to the target's timeout, including reading the body
+func (h *HTTP) do(req *http.Request, config *Config) (*http.Response, error) {
+	client := *h.client
+	client.Timeout = time.Duration(config.Timeout) * time.Second
+
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+		if err == nil {
+			return resp, nil
+		}
+
+		if !isDNSFailure(err) {
+			return nil, fmt.Errorf("failed to fetch page: %w", &TransientError{Err: err})
+		}
+
+		if attempt >= config.DNSRetries {
+			return nil, fmt.Errorf("failed to fetch page: %w", &DNSError{Err: err})
+		}
+		time.Sleep(time.Duration(1<<attempt) * time.Second)
+	}
+}
+
+// retryAfter returns the delay a 429 or 503 response asks for in its Retry-After header
+// The header holds either seconds or an HTTP date, dates in the past mean no delay
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// statusError describes an error status, including how long the server asked to wait if it did
+func statusError(resp *http.Response) error {
+	if delay, ok := retryAfter(resp, time.Now()); ok {
+		return fmt.Errorf("HTTP %d, retry after %v", resp.StatusCode, delay.Round(time.Second))
+	}
+	return fmt.Errorf("HTTP %d", resp.StatusCode)
+}
+
+// queryNodes returns the nodes matching the search's XPath or CSS selector
+func queryNodes(doc *html.Node, searchConfig *SearchConfig) ([]*html.Node, error) {
+	if searchConfig.CSS != "" {
+		selector, err := cascadia.Parse(searchConfig.CSS)
+		if err != nil {
+			return nil, err
+		}
+		return cascadia.QueryAll(doc, selector), nil
+	}
+	return htmlquery.QueryAll(doc, searchConfig.XPath)
+}
+
+// selectedNodesContent returns the content of the first selected node
+// With match_all_elements the content of every selected node is joined by newlines
+func selectedNodesContent(nodes []*html.Node, searchConfig *SearchConfig) (string, error) {
+	if !searchConfig.MatchAllElements {
+		nodes = nodes[:1]
+	}
+
+	texts := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		text, err := nodeContent(node, searchConfig)
+		if err != nil {
+			return "", err
+		}
+		texts = append(texts, text)
+	}
+	return strings.Join(texts, "\n"), nil
+}
+
+// nodeContent returns the text of a selected node, or the configured attribute instead
+func nodeContent(node *html.Node, searchConfig *SearchConfig) (string, error) {
+	if searchConfig.Attribute == "" {
+		return extractText(node), nil
+	}
+
+	// Entities are decoded by the parser, non-breaking spaces are normalized like the browser's content
+	for _, attribute := range node.Attr {
+		if attribute.Key == strings.ToLower(searchConfig.Attribute) {
+			return normalizeSpaces(attribute.Val), nil
+		}
+	}
+	return "", fmt.Errorf("element matched by %s has no %q attribute", searchConfig.describeSelector(), searchConfig.Attribute)
+}
+
+// checkNodeContent returns the text a check searches, nil when its XPath matches nothing
+func checkNodeContent(doc *html.Node, xpath string) (*string, error) {
+	if xpath == "" {
+		text := extractText(bodyNode(doc))
+		return &text, nil
+	}
+
+	node, err := htmlquery.Query(doc, xpath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find XPath elements: %w", err)
+	}
+	if node == nil {
+		return nil, nil
+	}
+	text := extractText(node)
+	return &text, nil
+}
+
+// bodyNode returns the body element, or the whole document if there is none
+func bodyNode(doc *html.Node) *html.Node {
+	if body := htmlquery.FindOne(doc, "//body"); body != nil {
+		return body
+	}
+	return doc
+}
+
+// skippedTextElements hold content that a browser never renders as text
+var skippedTextElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "head": true,
+}
+
+// blockTextElements start a new line in the extracted text, like they do in a browser
+var blockTextElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "dd": true,
+	"div": true, "dl": true, "dt": true, "fieldset": true, "figcaption": true, "figure": true,
+	"footer": true, "form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+	"h6": true, "header": true, "hr": true, "li": true, "main": true, "nav": true, "ol": true,
+	"p": true, "pre": true, "section": true, "table": true, "td": true, "th": true, "tr": true,
+	"ul": true,
+}
+
+// extractText returns the visible text of a node, approximating the browser's innerText
+// Entities are already decoded by the HTML parser
+func extractText(node *html.Node) string {
+	var b strings.Builder
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if skippedTextElements[n.Data] {
+				return
+			}
+		}
+
+		block := n.Type == html.ElementNode && blockTextElements[n.Data]
+		if block {
+			b.WriteString("\n")
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if block {
+			b.WriteString("\n")
+		}
+	}
+	walk(node)
+
+	// Collapse whitespace within lines and drop empty ones
+	var lines []string
+	for _, line := range strings.Split(b.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// jsonLDAvailability finds the availability property in a JSON-LD script
+var jsonLDAvailability = regexp.MustCompile(`"availability"\s*:\s*"([^"]*)"`)
+
+// structuredAvailability reads the schema.org availability from microdata or JSON-LD, if the page has any
+// Mirrors availabilityScript for pages fetched without a browser
+func structuredAvailability(doc *html.Node) string {
+	if node := htmlquery.FindOne(doc, `//*[@itemprop="availability"]`); node != nil {
+		for _, attribute := range []string{"href", "content"} {
+			if value := htmlquery.SelectAttr(node, attribute); value != "" {
+				return value
+			}
+		}
+		return htmlquery.InnerText(node)
+	}
+
+	for _, script := range htmlquery.Find(doc, `//script[@type="application/ld+json"]`) {
+		if match := jsonLDAvailability.FindStringSubmatch(htmlquery.InnerText(script)); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// collectLinks returns the absolute URLs of all links or images on the page, in document order
+// Mirrors linksScript for pages fetched without a browser
+func collectLinks(doc *html.Node, pageURL *url.URL, source string) []string {
+	xpath, attribute := "//a[@href]", "href"
+	if source == "images" {
+		xpath, attribute = "//img[@src]", "src"
+	}
+
+	// A <base> element changes what relative links resolve against
+	base := pageURL
+	if node := htmlquery.FindOne(doc, "//base[@href]"); node != nil {
+		if href, err := pageURL.Parse(htmlquery.SelectAttr(node, "href")); err == nil {
+			base = href
+		}
+	}
+
+	var links []string
+	for _, node := range htmlquery.Find(doc, xpath) {
+		link, err := base.Parse(strings.TrimSpace(htmlquery.SelectAttr(node, attribute)))
+		if err != nil {
+			continue
+		}
+		links = append(links, link.String())
+	}
+	return links
+}
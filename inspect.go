@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-rod/rod"
+)
+
+// inspectSampleElements is how many matching elements -inspect shows per selector
+const inspectSampleElements = 3
+
+// inspectSampleLength caps every extracted sample printed by -inspect
+const inspectSampleLength = 300
+
+// elementAttributesScript returns all attributes of an element as a name to value map
+const elementAttributesScript = `() => Object.fromEntries(Array.from(this.attributes, a => [a.name, a.value]))`
+
+// Inspection reports what each extraction mode yields for a page
+// Used by -inspect to help pick the right selector when setting up a monitor
+type Inspection struct {
+	URL       string
+	Title     string
+	BodyText  string
+	Selectors []SelectorInspection
+	Regex     *RegexInspection
+}
+
+// SelectorInspection reports how many elements an XPath or CSS selector matched and samples of each
+type SelectorInspection struct {
+	Name     string // Config key the selector comes from
+	Selector string
+	Count    int
+	Elements []ElementSample
+	Error    error
+}
+
+// ElementSample holds the text, html and attribute extraction of one element
+type ElementSample struct {
+	Text       string
+	HTML       string
+	Attributes map[string]string
+}
+
+// RegexInspection reports what extract_regex pulls out of the page source
+type RegexInspection struct {
+	Pattern   string
+	Extracted string
+	Error     error
+}
+
+// Inspect loads the configured page once and reports every extraction mode
+// Nothing is searched and no notifications are sent
+func (b *Browser) Inspect(config *Config) (*Inspection, error) {
+	page, _, closePage, err := b.openPage(config)
+	if err != nil {
+		return nil, err
+	}
+	defer closePage()
+
+	info, err := page.Info()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page info: %w", err)
+	}
+	inspection := &Inspection{URL: info.URL, Title: info.Title}
+
+	body, err := page.Element("body")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find page body: %w", err)
+	}
+	if inspection.BodyText, err = body.Text(); err != nil {
+		return nil, fmt.Errorf("failed to read page body: %w", err)
+	}
+
+	// Inspect the top-level selector and every named check's selector
+	if config.SearchConfig.XPath != "" {
+		inspection.Selectors = append(inspection.Selectors, inspectSelector("search.xpath", config.SearchConfig.XPath, page.ElementsX))
+	}
+	if config.SearchConfig.CSS != "" {
+		inspection.Selectors = append(inspection.Selectors, inspectSelector("search.css", config.SearchConfig.CSS, page.Elements))
+	}
+	if relation := config.SearchConfig.Relation; relation != nil {
+		inspection.Selectors = append(inspection.Selectors,
+			inspectSelector("relation.left", relation.Left, page.ElementsX),
+			inspectSelector("relation.right", relation.Right, page.ElementsX))
+	}
+	names := make([]string, 0, len(config.SearchConfig.Checks))
+	for name := range config.SearchConfig.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if xpath := config.SearchConfig.Checks[name].XPath; xpath != "" {
+			inspection.Selectors = append(inspection.Selectors, inspectSelector("checks."+name, xpath, page.ElementsX))
+		}
+	}
+
+	if config.SearchConfig.ExtractRegex != "" {
+		regex := &RegexInspection{Pattern: config.SearchConfig.ExtractRegex}
+		raw, err := page.HTML()
+		if err != nil {
+			regex.Error = fmt.Errorf("failed to read page source: %w", err)
+		} else {
+			regex.Extracted, regex.Error = extractWithRegex(raw, &config.SearchConfig)
+		}
+		inspection.Regex = regex
+	}
+
+	return inspection, nil
+}
+
+// inspectSelector samples the text, html and attributes of the first elements a selector matches
+// find is page.ElementsX for XPath or page.Elements for CSS
+func inspectSelector(name, expression string, find func(string) (rod.Elements, error)) SelectorInspection {
+	selector := SelectorInspection{Name: name, Selector: expression}
+
+	elements, err := find(expression)
+	if err != nil {
+		selector.Error = fmt.Errorf("failed to find elements: %w", err)
+		return selector
+	}
+	selector.Count = len(elements)
+
+	for _, element := range elements[:min(len(elements), inspectSampleElements)] {
+		var sample ElementSample
+		sample.Text, _ = element.Text()
+		sample.HTML, _ = element.HTML()
+		if attributes, err := element.Eval(elementAttributesScript); err == nil {
+			_ = attributes.Value.Unmarshal(&sample.Attributes)
+		}
+		selector.Elements = append(selector.Elements, sample)
+	}
+	return selector
+}
+
+// writeInspection prints an inspection report in a human readable layout
+func writeInspection(w io.Writer, inspection *Inspection) {
+	fmt.Fprintf(w, "URL:   %s\n", inspection.URL)
+	fmt.Fprintf(w, "Title: %s\n\n", inspection.Title)
+
+	fmt.Fprintf(w, "Body text (%d characters):\n%s\n", utf8.RuneCountInString(inspection.BodyText), indentSample(inspection.BodyText))
+
+	for _, selector := range inspection.Selectors {
+		fmt.Fprintf(w, "\n%s %q: ", selector.Name, selector.Selector)
+		if selector.Error != nil {
+			fmt.Fprintf(w, "error: %v\n", selector.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%d element(s) matched\n", selector.Count)
+
+		for i, element := range selector.Elements {
+			fmt.Fprintf(w, "  [%d] text:\n%s\n", i+1, indentSample(element.Text))
+			fmt.Fprintf(w, "      html:\n%s\n", indentSample(element.HTML))
+
+			attributes := make([]string, 0, len(element.Attributes))
+			for name := range element.Attributes {
+				attributes = append(attributes, name)
+			}
+			sort.Strings(attributes)
+			fmt.Fprintf(w, "      attributes:\n")
+			for _, attribute := range attributes {
+				fmt.Fprintf(w, "        %s = %q\n", attribute, element.Attributes[attribute])
+			}
+		}
+		if selector.Count > len(selector.Elements) {
+			fmt.Fprintf(w, "  ... %d more\n", selector.Count-len(selector.Elements))
+		}
+	}
+
+	if regex := inspection.Regex; regex != nil {
+		fmt.Fprintf(w, "\nextract_regex %q: ", regex.Pattern)
+		if regex.Error != nil {
+			fmt.Fprintf(w, "error: %v\n", regex.Error)
+		} else {
+			fmt.Fprintf(w, "extracted\n%s\n", indentSample(regex.Extracted))
+		}
+	}
+}
+
+// indentSample shortens extracted content to whole characters and indents it below its label
+func indentSample(sample string) string {
+	sample = strings.TrimSpace(sample)
+	if utf8.RuneCountInString(sample) > inspectSampleLength {
+		sample = string([]rune(sample)[:inspectSampleLength]) + "..."
+	}
+	if sample == "" {
+		sample = "(empty)"
+	}
+	return "        " + strings.ReplaceAll(sample, "\n", "\n        ")
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// jsonPathStep is one segment of a parsed JSONPath expression
+type jsonPathStep struct {
+	key       string // Object member to select, empty for index and wildcard steps
+	index     int    // Array element to select, negative counts from the end
+	isIndex   bool
+	wildcard  bool // Selects every member or element
+	recursive bool // Applies the step at any depth below the current values, written as ..
+}
+
+// parseJSONPath parses a JSONPath expression such as "$.items[0].price" or "$..name"
+// Supports member names, quoted names, array indexes, wildcards and recursive descent, but no filters
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("jsonpath must start with $")
+	}
+
+	var steps []jsonPathStep
+	rest := path[1:]
+	for rest != "" {
+		var step jsonPathStep
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			step.recursive = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				break
+			}
+			fallthrough
+		case strings.HasPrefix(rest, "."):
+			rest = strings.TrimPrefix(rest, ".")
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			if name == "" {
+				return nil, fmt.Errorf("missing member name in %q", path)
+			}
+			step.key, step.wildcard = name, name == "*"
+			rest = rest[end:]
+			steps = append(steps, step)
+			continue
+		case !strings.HasPrefix(rest, "["):
+			return nil, fmt.Errorf("unexpected %q in %q", rest, path)
+		}
+
+		// Bracket notation: ['name'], ["name"], [0], [-1] or [*]
+		end := strings.Index(rest, "]")
+		if end < 0 {
+			return nil, fmt.Errorf("missing ] in %q", path)
+		}
+		selector := strings.TrimSpace(rest[1:end])
+		rest = rest[end+1:]
+
+		switch {
+		case selector == "*":
+			step.wildcard = true
+		case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+			step.key = selector[1 : len(selector)-1]
+		default:
+			index, err := strconv.Atoi(selector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector [%s] in %q, expected a quoted name, an index or *", selector, path)
+			}
+			step.index, step.isIndex = index, true
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// evaluateJSONPath returns every value the steps select from a decoded JSON document, in document order
+func evaluateJSONPath(document any, steps []jsonPathStep) []any {
+	values := []any{document}
+	for _, step := range steps {
+		var next []any
+		for _, value := range values {
+			if step.recursive {
+				for _, descendant := range jsonDescendants(value) {
+					next = append(next, step.apply(descendant)...)
+				}
+				continue
+			}
+			next = append(next, step.apply(value)...)
+		}
+		values = next
+	}
+	return values
+}
+
+// apply selects the children of a single value matched by the step
+func (step jsonPathStep) apply(value any) []any {
+	switch typed := value.(type) {
+	case map[string]any:
+		if step.wildcard {
+			return jsonChildren(typed)
+		}
+		if child, ok := typed[step.key]; ok && !step.isIndex {
+			return []any{child}
+		}
+	case []any:
+		if step.wildcard {
+			return typed
+		}
+		if step.isIndex {
+			index := step.index
+			if index < 0 {
+				index += len(typed)
+			}
+			if index >= 0 && index < len(typed) {
+				return []any{typed[index]}
+			}
+		}
+	}
+	return nil
+}
+
+// jsonDescendants returns a value followed by everything nested in it, for recursive descent
+func jsonDescendants(value any) []any {
+	descendants := []any{value}
+	var children []any
+	switch typed := value.(type) {
+	case map[string]any:
+		children = jsonChildren(typed)
+	case []any:
+		children = typed
+	}
+	for _, child := range children {
+		descendants = append(descendants, jsonDescendants(child)...)
+	}
+	return descendants
+}
+
+// jsonChildren returns the members of an object ordered by key, since Go maps have no order
+func jsonChildren(object map[string]any) []any {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	children := make([]any, len(keys))
+	for i, key := range keys {
+		children[i] = object[key]
+	}
+	return children
+}
+
+// jsonText renders a selected value for searching: strings as is, numbers as written, anything else as compact JSON
+func jsonText(value any) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+	// Keep markup in nested strings readable instead of escaping it as \u003c
+	var encoded strings.Builder
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSuffix(encoded.String(), "\n")
+}
+
+// extractWithJSONPath parses a JSON response and returns the value the configured jsonpath selects
+// Only the first value is used unless match_all_elements is set, no match is handled like an empty XPath
+func extractWithJSONPath(raw string, searchConfig *SearchConfig) (string, error) {
+	steps, err := parseJSONPath(searchConfig.JSONPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid jsonpath: %w", err)
+	}
+
+	// Keep numbers as written, so 19.90 isn't reported as 19.9
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return "", fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	values := evaluateJSONPath(document, steps)
+	if len(values) > 0 {
+		if !searchConfig.MatchAllElements {
+			values = values[:1]
+		}
+		texts := make([]string, len(values))
+		for i, value := range values {
+			texts[i] = jsonText(value)
+		}
+		return strings.Join(texts, "\n"), nil
+	}
+
+	switch searchConfig.OnEmptyExtraction {
+	case "fallback-body":
+		return raw, nil
+	case "empty":
+		return "", nil
+	default:
+		return "", fmt.Errorf("jsonpath %q matched nothing", searchConfig.JSONPath)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// linksScript returns the absolute URLs of all links or images on the page, in document order
+const linksScript = `(source) => source === 'images'
+	? Array.from(document.querySelectorAll('img[src]'), img => img.src)
+	: Array.from(document.querySelectorAll('a[href]'), a => a.href)`
+
+// EvaluateLinks reports links that are not in the seen-set and adds them to it
+// The baseline check of a page only fills the seen-set, so existing links don't alert
+func EvaluateLinks(links []string, pattern string, seen map[string]bool, baseline bool) (bool, []string, error) {
+	var filter *regexp.Regexp
+	if pattern != "" {
+		var err error
+		if filter, err = regexp.Compile(pattern); err != nil {
+			return false, nil, fmt.Errorf("invalid link pattern: %w", err)
+		}
+	}
+
+	newLinks := []string{}
+	for _, link := range links {
+		if seen[link] || (filter != nil && !filter.MatchString(link)) {
+			continue
+		}
+		seen[link] = true
+		if !baseline {
+			newLinks = append(newLinks, link)
+		}
+	}
+	return len(newLinks) > 0, newLinks, nil
+}
@@ -1,73 +1,365 @@
 package main
 
 import (
+	"cmp"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"maps"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"reflect"
+	"regexp"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Timezone names also work on systems without a zoneinfo database, such as Windows
+
+	"github.com/andybalholm/cascadia"
 )
 
 func main() {
+	// Deferred first so a non-zero exit still runs every other deferred cleanup
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+
 	// Parse command line flags for configuration file and execution mode
 	var configFile string
 	var runOnce bool
+	var showVersion bool
+	var checkUpdate bool
+	var outputFormat string
+	var renderMessage bool
+	var inspect bool
+	var strictConfig bool
+	var printConfig bool
+	var showSecrets bool
+	var dryRun bool
+	var debugContent bool
+	var validateOnly bool
 
 	flag.StringVar(&configFile, "config", "config.json", "Path to config file.")
 	flag.BoolVar(&runOnce, "once", false, "Run once and exit.")
+	flag.BoolVar(&showVersion, "version", false, "Print version and exit.")
+	flag.BoolVar(&checkUpdate, "check-update", false, "Check GitHub for a newer release and exit.")
+	flag.StringVar(&outputFormat, "format", "text", "Result output format with -once: a text summary, json, yaml or xml.")
+	flag.BoolVar(&renderMessage, "render-message", false, "Print the message each channel would receive for sample results and exit.")
+	flag.BoolVar(&inspect, "inspect", false, "Fetch the page once, print what each extraction mode yields and exit.")
+	flag.BoolVar(&strictConfig, "strict", true, "Reject unknown fields in the config file.")
+	flag.BoolVar(&printConfig, "print-config", false, "Print the config with defaults applied as json, or yaml with -format yaml, and exit.")
+	flag.BoolVar(&showSecrets, "show-secrets", false, "Show passwords, tokens and webhook URLs in -print-config output.")
+	flag.BoolVar(&debugContent, "debug", false, "Fetch every target once, print the extracted content, matches and search outcome without notifying, and exit.")
+	flag.BoolVar(&validateOnly, "validate", false, "Check the config file for errors without fetching anything, and exit.")
+	flag.BoolVar(&dryRun, "dry-run", false, "Log the message each channel would receive instead of sending notifications.")
+	flag.BoolVar(&verbose, "verbose", false, "Log debug details such as skipped ticks.")
 	flag.Parse()
 
+	// Handle informational flags before any config is loaded
+	if showVersion {
+		fmt.Printf("UpToDate %s\n", version)
+		return
+	}
+
+	if checkUpdate {
+		status, err := checkForUpdate()
+		if err != nil {
+			log.Fatalf("Failed to check for updates: %v", err)
+		}
+		fmt.Println(status)
+		return
+	}
+
+	if !slices.Contains(outputFormats, outputFormat) {
+		log.Fatalf("Invalid format %q, expected one of %v", outputFormat, outputFormats)
+	}
+
 	// Load JSON configuration from file and validate all settings
-	config, err := LoadConfig(configFile)
+	config, monitors, err := loadMonitors(configFile, strictConfig)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	if err := validateConfig(config); err != nil {
-		log.Fatalf("Invalid configuration: %v", err)
+	// Loading already validated every monitor, so a broken config has exited by now
+	if validateOnly {
+		fmt.Printf("%s is valid, %d monitor(s)\n", configFile, len(monitors))
+		return
+	}
+
+	// Show the effective configuration, json unless yaml was asked for
+	if printConfig {
+		format := outputFormat
+		if format == "text" {
+			format = "json"
+		}
+		if err := writeConfig(os.Stdout, format, monitors, showSecrets); err != nil {
+			log.Fatalf("Failed to print config: %v", err)
+		}
+		return
 	}
 
-	// Create browser instance and notification service from config
-	client := NewBrowser()
+	// Preview rendered messages without fetching or sending anything
+	if renderMessage {
+		for _, monitor := range monitors {
+			for _, search := range notifyingConfigs(monitor) {
+				renderSampleMessages(NewNotificationService(search), search)
+			}
+		}
+		return
+	}
+
+	// Open the SSH tunnel before anything tries to reach the monitored page
+	if config.SSHTunnel != nil {
+		tunnel, err := StartSSHTunnel(config.SSHTunnel)
+		if err != nil {
+			log.Fatalf("Failed to start SSH tunnel: %v", err)
+		}
+		defer tunnel.Close()
+	}
+
+	// Report extraction details without searching or notifying
+	if inspect {
+		if monitors[0].FetchMode != "browser" {
+			log.Fatalf("-inspect requires fetch_mode browser")
+		}
+		browser, err := NewBrowser(monitors[0])
+		if err != nil {
+			log.Fatalf("Failed to start browser: %v", err)
+		}
+		defer browser.Close()
+
+		for i, monitor := range monitors {
+			if i > 0 {
+				fmt.Println()
+			}
+			inspection, err := browser.Inspect(monitor)
+			if err != nil {
+				log.Fatalf("Failed to inspect %s: %v", monitor.Label(), err)
+			}
+			writeInspection(os.Stdout, inspection)
+		}
+		return
+	}
+
+	// Create one fetch client shared by all targets, each target keeps its own notification state
+	client := newClient(monitors[0])
 	defer client.Close()
 
-	notificationService := NewNotificationService(config)
+	// Show the exact content the search runs on, without notifying anyone
+	if debugContent {
+		for i, monitor := range monitors {
+			if i > 0 && outputFormat == "text" {
+				fmt.Println()
+			}
+			result := runFetch(client, nil, monitor)
+			if err := writeDebugResult(os.Stdout, outputFormat, monitor, result); err != nil {
+				log.Fatalf("Failed to write result: %v", err)
+			}
+		}
+		return
+	}
 
-	log.Printf("Starting UpToDate monitoring for: %s", config.URL)
-	log.Printf("Search type: %s, pattern: %s", config.SearchConfig.Type, config.SearchConfig.Pattern)
-	log.Printf("Notify on: %s", config.SearchConfig.NotifyOn)
+	notificationServices := newNotificationServices(monitors, nil, dryRun)
+	if dryRun {
+		log.Printf("Dry run: notifications are logged instead of sent")
+	}
+
+	log.Printf("Starting UpToDate %s monitoring for: %s", version, monitorLabels(monitors))
+	for _, monitor := range monitors {
+		for _, search := range notifyingConfigs(monitor) {
+			log.Printf("%sSearch type: %s, pattern: %s", logPrefix(search), search.SearchConfig.Type, search.SearchConfig.Pattern)
+			log.Printf("%sNotify on: %s", logPrefix(search), search.SearchConfig.NotifyOn)
+			filters := search.Notifications.filters()
+			for _, channel := range slices.Sorted(maps.Keys(filters)) {
+				if events := filters[channel].NotifyOn; len(events) > 0 {
+					log.Printf("%sNotify %s on: %s", logPrefix(search), channel, strings.Join(events, ", "))
+				}
+			}
+		}
+	}
+
+	// Show the effective proxy per target, environment variables are easy to get wrong
+	if httpClient, ok := client.(*HTTP); ok {
+		log.Printf("TLS: %s", describeTLS(monitors[0].TLS))
+		for _, monitor := range monitors {
+			log.Printf("%sProxy: %s", logPrefix(monitor), httpClient.describeProxy(monitor.URL))
+		}
+	} else if proxyURL, err := url.Parse(monitors[0].Proxy); err == nil && monitors[0].Proxy != "" {
+		proxyURL.User = nil // Keep credentials out of the log
+		log.Printf("Proxy: %s", proxyURL)
+	}
 
 	// Execute single fetch when -once flag is provided
 	if runOnce {
-		runFetch(client, notificationService, config)
+		for i, monitor := range monitors {
+			result := runFetch(client, notificationServices[i], monitor)
+			if err := writeResult(os.Stdout, outputFormat, monitor, result); err != nil {
+				log.Fatalf("Failed to write result: %v", err)
+			}
+		}
 		return
 	}
 
-	// Set up signal handling for graceful shutdown and configure monitoring interval
+	// Set up signal handling for graceful shutdown
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
-	interval := time.Duration(config.Interval) * time.Second
-	if interval == 0 {
-		interval = 300 * time.Second // Default to 5 minutes
+	// Each target has its own timer, fetches run one at a time in the loop below
+	schedules := make([]*pollSchedule, len(monitors))
+	triggers := make([]*triggerGate, len(monitors))
+	for i, monitor := range monitors {
+		schedules[i] = newPollSchedule(monitor)
+		triggers[i] = newTriggerGate(monitor)
+		logSchedule(monitor, schedules[i])
 	}
+	timers := startMonitorTimers(schedules)
+	defer func() { timers.stop() }()
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+	// The heartbeat reports on its own timer that monitoring is alive, whatever the pages show
+	alive := newHeartbeat(monitors, nil, dryRun)
+	var heartbeatTicker *time.Ticker
+	var heartbeatTicks <-chan time.Time
+	defer func() {
+		if heartbeatTicker != nil {
+			heartbeatTicker.Stop()
+		}
+	}()
+	if alive != nil {
+		heartbeatTicker = time.NewTicker(alive.interval)
+		heartbeatTicks = heartbeatTicker.C
+		log.Printf("Sending a heartbeat every %v", alive.interval)
+	}
 
-	log.Printf("Monitoring every %v", interval)
+	// Reload signals swap in an edited config file, keeping the fetch client and notification state
+	reload := make(chan os.Signal, 1)
+	if len(reloadSignals) > 0 {
+		signal.Notify(reload, reloadSignals...)
+	}
 
-	// Run first fetch immediately before starting timer
-	runFetch(client, notificationService, config)
+	// Pause signals stop scheduled fetches without losing in-memory state
+	pause := make(chan os.Signal, 1)
+	if len(pauseSignals) > 0 {
+		signal.Notify(pause, pauseSignals...)
+	}
+	paused := false
+
+	// Count failed fetches in a row across all targets so a persistently broken setup can exit for its supervisor
+	consecutiveErrors := 0
+	fetch := func(i int) bool {
+		// An external process can hold back scheduled fetches through the trigger file
+		if triggers[i] != nil {
+			if ok, reason := triggers[i].ready(); !ok {
+				debugf("%sSkipping tick, %s", logPrefix(monitors[i]), reason)
+				timers.reset(i, schedules[i].wait())
+				return false
+			}
+		}
+
+		result := runFetch(client, notificationServices[i], monitors[i])
+		if alive != nil {
+			alive.record(monitors[i], result)
+		}
+		if result.Error != nil {
+			consecutiveErrors++
+		} else {
+			consecutiveErrors = 0
+		}
+		timers.reset(i, schedules[i].next(result))
+		return config.MaxConsecutiveErrors > 0 && consecutiveErrors >= config.MaxConsecutiveErrors
+	}
+	giveUp := func(i int) {
+		err := fmt.Errorf("exiting after %d consecutive fetch errors", consecutiveErrors)
+		log.Println(err)
+		if err := notificationServices[i].SendNotification(&Result{Error: err}); err != nil {
+			log.Printf("Notification error: %v", err)
+		}
+		exitCode = 1
+	}
+
+	// Run first fetch of every target immediately before the timers fire
+	for i := range monitors {
+		if fetch(i) {
+			giveUp(i)
+			return
+		}
+	}
 
 	// Wait for timer ticks or shutdown signals in infinite loop
 	for {
 		select {
-		case <-ticker.C:
-			runFetch(client, notificationService, config)
+		case i := <-timers.due:
+			if paused {
+				timers.reset(i, schedules[i].wait())
+				continue
+			}
+			if fetch(i) {
+				giveUp(i)
+				return
+			}
+		case <-heartbeatTicks:
+			alive.send(monitors, paused)
+		case <-pause:
+			paused = !paused
+			if paused {
+				log.Println("Monitoring paused, send the signal again to resume")
+			} else {
+				log.Println("Monitoring resumed")
+			}
+		case <-reload:
+			reloadedConfig, reloadedMonitors, err := loadMonitors(configFile, strictConfig)
+			if err == nil {
+				if changed := restartRequired(monitors[0], reloadedMonitors[0]); len(changed) > 0 {
+					err = fmt.Errorf("%s changed, restart to apply", strings.Join(changed, ", "))
+				}
+			}
+			if err != nil {
+				log.Printf("Reload failed, keeping the current configuration: %v", err)
+				continue
+			}
+
+			// Restart every timer at the new interval, ticks of the old config are discarded
+			timers.stop()
+			config, monitors = reloadedConfig, reloadedMonitors
+			notificationServices = newNotificationServices(monitors, notificationServices, dryRun)
+			if browser, ok := client.(*Browser); ok {
+				browser.closeStaleTabs(monitors)
+			}
+			schedules = make([]*pollSchedule, len(monitors))
+			triggers = make([]*triggerGate, len(monitors))
+			for i, monitor := range monitors {
+				schedules[i] = newPollSchedule(monitor)
+				triggers[i] = newTriggerGate(monitor)
+			}
+			timers = startMonitorTimers(schedules)
+
+			// Keep the heartbeat's schedule unless its interval changed
+			var previousInterval time.Duration
+			if alive != nil {
+				previousInterval = alive.interval
+			}
+			alive = newHeartbeat(monitors, alive, dryRun)
+			switch {
+			case alive == nil && heartbeatTicker != nil:
+				heartbeatTicker.Stop()
+				heartbeatTicker, heartbeatTicks = nil, nil
+			case alive != nil && heartbeatTicker == nil:
+				heartbeatTicker = time.NewTicker(alive.interval)
+				heartbeatTicks = heartbeatTicker.C
+			case alive != nil && alive.interval != previousInterval:
+				heartbeatTicker.Reset(alive.interval)
+			}
+
+			log.Printf("Configuration reloaded, monitoring: %s", monitorLabels(monitors))
+			for i, monitor := range monitors {
+				logSchedule(monitor, schedules[i])
+			}
 		case <-c:
 			log.Println("Received shutdown signal, exiting...")
 			return
@@ -75,46 +367,325 @@ func main() {
 	}
 }
 
+// verbose enables debug logging, set by the -verbose flag
+var verbose bool
+
+// debugf logs a message only when -verbose is set
+func debugf(format string, args ...any) {
+	if verbose {
+		log.Printf(format, args...)
+	}
+}
+
+// loadMonitors loads the config file and validates the config of every monitored page
+// Used at startup and again for every reload signal
+func loadMonitors(configFile string, strict bool) (*Config, []*Config, error) {
+	config, err := LoadConfig(configFile, strict)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Validate every monitored page on its own, targets share everything else
+	// Errors of all targets are reported together, so one run shows everything to fix
+	if len(config.Targets) > 0 && (config.URL != "" || config.Name != "") {
+		return nil, nil, fmt.Errorf("invalid configuration: url and name move into targets when targets are used")
+	}
+	monitors := config.Monitors()
+	var errs []error
+	for _, monitor := range monitors {
+		if err := validateConfig(monitor); err != nil {
+			if len(config.Targets) > 0 {
+				errs = append(errs, fmt.Errorf("invalid configuration for target %s: %w", monitor.Label(), err))
+				continue
+			}
+			errs = append(errs, fmt.Errorf("invalid configuration: %w", err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+
+	// Load change detection and release state up front so a corrupt state file fails right away
+	for _, monitor := range monitors {
+		if monitor.FetchMode == "github" || slices.Contains(monitor.Notifications.events(monitor.SearchConfig.NotifyOn), "change") {
+			if _, err := loadStateStore(monitor.StateFile); err != nil {
+				return nil, nil, fmt.Errorf("failed to load state: %w", err)
+			}
+		}
+	}
+	return config, monitors, nil
+}
+
+// validateSMTPTLSMode checks how an SMTP connection is secured
+func validateSMTPTLSMode(mode string) error {
+	switch mode {
+	case "starttls", "tls", "none":
+		return nil
+	default:
+		return fmt.Errorf("invalid tls_mode %q, expected starttls, tls or none", mode)
+	}
+}
+
+// validateSMTPCredentials checks that a server's credentials fit its TLS mode
+// Without TLS no credentials are needed, and they can't be sent anywhere but to a local relay
+func validateSMTPCredentials(host, mode, username, password string) error {
+	if mode != "none" {
+		if username == "" || password == "" {
+			return fmt.Errorf("email configuration is incomplete")
+		}
+		return nil
+	}
+	if username != "" && !slices.Contains([]string{"localhost", "127.0.0.1", "::1"}, host) {
+		return fmt.Errorf("credentials for %s can't be sent with tls_mode none, use starttls or tls or leave out username and password", host)
+	}
+	return nil
+}
+
+// defaultSMTPPort returns the submission port for a TLS mode, 465 for implicit TLS and 587 otherwise
+func defaultSMTPPort(mode string) int {
+	if mode == "tls" {
+		return 465
+	}
+	return 587
+}
+
+// restartRequired lists the changed settings that the shared fetch client or SSH tunnel was built from
+// A reload can't apply them without replacing the running browser
+func restartRequired(current, reloaded *Config) []string {
+	var changed []string
+	if current.FetchMode != reloaded.FetchMode {
+		changed = append(changed, "fetch_mode")
+	}
+	if current.UserDataDir != reloaded.UserDataDir {
+		changed = append(changed, "user_data_dir")
+	}
+	if current.Proxy != reloaded.Proxy {
+		changed = append(changed, "proxy")
+	}
+	if !reflect.DeepEqual(current.TLS, reloaded.TLS) {
+		changed = append(changed, "tls")
+	}
+	if !reflect.DeepEqual(current.SSHTunnel, reloaded.SSHTunnel) {
+		changed = append(changed, "ssh_tunnel")
+	}
+
+	// The HTTP client's transport and cookie jar are built once, the browser reads both per page
+	if current.FetchMode == "http" {
+		if !reflect.DeepEqual(current.UseEnvProxy, reloaded.UseEnvProxy) {
+			changed = append(changed, "use_env_proxy")
+		}
+		if !reflect.DeepEqual(current.Cookies, reloaded.Cookies) {
+			changed = append(changed, "cookies")
+		}
+	}
+	return changed
+}
+
+// newNotificationServices creates a notification service per monitor
+// Services of a previous config pass their state on to the monitor with the same label
+func newNotificationServices(monitors []*Config, previous []*NotificationService, dryRun bool) []*NotificationService {
+	services := make([]*NotificationService, len(monitors))
+	for i, monitor := range monitors {
+		services[i] = NewNotificationService(monitor)
+		services[i].SetDryRun(dryRun)
+		for _, old := range previous {
+			if old.config.Label() == monitor.Label() {
+				services[i].inherit(old)
+			}
+		}
+	}
+	return services
+}
+
+// logSchedule logs how often a monitor is fetched
+func logSchedule(monitor *Config, schedule *pollSchedule) {
+	if monitor.AdaptiveInterval != nil {
+		log.Printf("%sMonitoring every %v, adapting between %v and %v", logPrefix(monitor), schedule.interval,
+			monitor.AdaptiveInterval.minInterval(), monitor.AdaptiveInterval.maxInterval())
+	} else {
+		log.Printf("%sMonitoring every %v", logPrefix(monitor), schedule.interval)
+	}
+	if monitor.Jitter > 0 {
+		log.Printf("%sVarying the delay between checks by up to %d%%", logPrefix(monitor), monitor.Jitter)
+	}
+}
+
+// monitorLabels joins the labels of all monitored pages for the startup log
+func monitorLabels(monitors []*Config) string {
+	labels := make([]string, len(monitors))
+	for i, monitor := range monitors {
+		labels[i] = monitor.Label()
+	}
+	return strings.Join(labels, ", ")
+}
+
+// notifyingConfigs returns the configs that notify about a monitor's search results
+// Named searches notify on their own, a monitor without them notifies itself
+func notifyingConfigs(monitor *Config) []*Config {
+	if searches := monitor.searchMonitors(); len(searches) > 0 {
+		return searches
+	}
+	return []*Config{monitor}
+}
+
+// logPrefix tags log lines with the target name when one is set
+func logPrefix(config *Config) string {
+	if config.Name == "" {
+		return ""
+	}
+	return fmt.Sprintf("[%s] ", config.Name)
+}
+
+// renderSampleMessages prints every channel's message for a synthetic match and a synthetic error
+func renderSampleMessages(notificationService *NotificationService, config *Config) {
+	samples := []struct {
+		name   string
+		result *Result
+	}{
+		{"pattern match", &Result{Found: true, Matches: []string{config.SearchConfig.Pattern}}},
+		{"fetch error", &Result{Error: fmt.Errorf("sample fetch error")}},
+	}
+
+	for _, sample := range samples {
+		for _, rendered := range notificationService.RenderMessages(sample.result) {
+			fmt.Printf("=== %s: %s ===\n%s\n\n", rendered.Channel, sample.name, rendered.Message)
+		}
+	}
+}
+
+// newClient creates the fetch client selected by fetch_mode
+func newClient(config *Config) Client {
+	switch config.FetchMode {
+	case "github":
+		return NewGitHubClient(config)
+	case "http":
+		client, err := NewHTTP(config)
+		if err != nil {
+			log.Fatalf("Failed to create HTTP client: %v", err)
+		}
+		return client
+	default:
+		browser, err := NewBrowser(config)
+		if err != nil {
+			log.Fatalf("Failed to start browser: %v", err)
+		}
+		return browser
+	}
+}
+
 // runFetch performs a single fetch operation and handles logging
-func runFetch(client Client, notificationService *NotificationService, config *Config) {
-	log.Printf("Fetching %s...", config.URL)
+func runFetch(client Client, notificationService *NotificationService, config *Config) *Result {
+	prefix := logPrefix(config)
+	log.Printf("%sFetching %s...", prefix, config.URL)
 
-	// Call browser client to fetch page and search for patterns
-	result := client.Fetch(config)
+	// Call fetch client to fetch page and search for patterns
+	result := fetchWithRetry(client, config)
+	sortMatches(result.Matches, config.SearchConfig.SortMatches)
+	if len(result.Searches) == 0 {
+		// Named searches already selected their own matches
+		result.Matches = selectMatches(result.Matches, config.SearchConfig.MatchSelect)
+	}
 
 	// Output search results and any regex matches to console
 	if result.Error != nil {
-		log.Printf("Fetch error: %v", result.Error)
+		log.Printf("%sFetch error: %v", prefix, result.Error)
 	} else {
-		status := "not found"
-		if result.Found {
-			status = "found"
-		}
-		
+		subject := searchSubject(config)
+		status := searchStatus(config, result.Found)
+
 		if result.Found && len(result.Matches) > 0 {
-			log.Printf("Pattern '%s' %s. Matches found:", config.SearchConfig.Pattern, status)
+			log.Printf("%s%s %s. Matches found:", prefix, subject, status)
 			for i, match := range result.Matches {
 				log.Printf("  [%d] %s", i+1, match)
 			}
 		} else {
-			log.Printf("Pattern '%s' %s", config.SearchConfig.Pattern, status)
+			log.Printf("%s%s %s", prefix, subject, status)
 		}
 	}
 
-	// Send notifications if conditions are met based on search outcome
-	if err := notificationService.SendNotification(result); err != nil {
-		log.Printf("Notification error: %v", err)
+	// Send notifications if conditions are met based on search outcome, -debug passes no service
+	if notificationService != nil {
+		if err := notificationService.SendNotification(result); err != nil {
+			log.Printf("%sNotification error: %v", prefix, err)
+		}
+	}
+
+	return result
+}
+
+// fetchWithRetry fetches once, retrying transient failures with exponential backoff when configured
+// Only the final attempt's result is reported
+func fetchWithRetry(client Client, config *Config) *Result {
+	for attempt := 1; ; attempt++ {
+		result := client.Fetch(config)
+
+		var transient *TransientError
+		if config.Retry == nil || attempt >= config.Retry.MaxAttempts || !errors.As(result.Error, &transient) {
+			return result
+		}
+
+		delay := time.Duration(config.Retry.BaseDelay) * time.Millisecond << (attempt - 1)
+		log.Printf("%sAttempt %d/%d failed, retrying in %v: %v", logPrefix(config), attempt, config.Retry.MaxAttempts, delay, result.Error)
+		time.Sleep(delay)
 	}
 }
 
 // validateConfig validates configuration and applies defaults
 // Checks required fields and sets defaults for missing optional values
 func validateConfig(config *Config) error {
+	// GitHub mode watches a repository instead of a page and needs no search pattern
+	switch config.FetchMode {
+	case "":
+		config.FetchMode = "browser"
+	case "browser":
+	case "http":
+		// Without a browser there are no navigation timings to search on
+		if strings.ToLower(config.SearchConfig.Type) == "perf" {
+			return fmt.Errorf("perf searches require fetch_mode browser")
+		}
+	case "github":
+		if err := validateGitHubConfig(config); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid fetch_mode %q, expected browser, http or github", config.FetchMode)
+	}
+
 	if config.URL == "" {
 		return fmt.Errorf("URL is required")
 	}
 
-	if config.SearchConfig.Pattern == "" {
+	// Element searches are driven by the selector alone, checks carry their own patterns, every other type needs a pattern
+	switch {
+	case config.FetchMode == "github":
+		// Found means a new version was published, there is nothing to search for
+		if len(config.SearchConfig.Searches) > 0 {
+			return fmt.Errorf("searches are not supported with fetch_mode github")
+		}
+	case len(config.SearchConfig.Checks) > 0:
+		if err := validateChecks(&config.SearchConfig); err != nil {
+			return err
+		}
+	case strings.ToLower(config.SearchConfig.Type) == "relation":
+		if err := validateRelation(&config.SearchConfig); err != nil {
+			return err
+		}
+	case len(config.SearchConfig.Searches) > 0:
+		if err := validateSearches(config); err != nil {
+			return err
+		}
+	case strings.ToLower(config.SearchConfig.Type) == "availability":
+		// Built-in heuristics decide, a pattern is not needed
+	case strings.ToLower(config.SearchConfig.Type) == "links":
+		// The pattern is an optional URL filter
+		if err := validateLinksSearch(&config.SearchConfig); err != nil {
+			return err
+		}
+	case strings.ToLower(config.SearchConfig.Type) == "element":
+		if config.SearchConfig.selector() == "" {
+			return fmt.Errorf("xpath or css is required for element searches")
+		}
+	case config.SearchConfig.Pattern == "":
 		return fmt.Errorf("search pattern is required")
 	}
 
@@ -130,38 +701,843 @@ func validateConfig(config *Config) error {
 		}
 	}
 
-	if config.SearchConfig.NotifyOn == "" {
+	// Compile regex patterns so a typo fails at startup instead of on every check
+	if strings.ToLower(config.SearchConfig.Type) == "regex" {
+		if _, err := regexp.Compile(config.SearchConfig.Pattern); err != nil {
+			return fmt.Errorf("invalid regex pattern: %w", err)
+		}
+	}
+
+	// Parse numeric patterns to validate operator and threshold
+	if strings.ToLower(config.SearchConfig.Type) == "numeric" {
+		if _, err := ParseNumericPattern(config.SearchConfig.Pattern); err != nil {
+			return fmt.Errorf("invalid numeric pattern: %w", err)
+		}
+	}
+
+	// Parse performance patterns to validate metric, operator and threshold
+	if strings.ToLower(config.SearchConfig.Type) == "perf" {
+		if _, err := ParsePerfPattern(config.SearchConfig.Pattern); err != nil {
+			return fmt.Errorf("invalid perf pattern: %w", err)
+		}
+	}
+
+	switch config.SearchConfig.OnEmptyExtraction {
+	case "":
+		config.SearchConfig.OnEmptyExtraction = "error"
+	case "error", "fallback-body", "empty":
+	default:
+		return fmt.Errorf("invalid on_empty_extraction %q, expected error, fallback-body or empty", config.SearchConfig.OnEmptyExtraction)
+	}
+
+	// Date searches need a layout and a valid comparison
+	if strings.ToLower(config.SearchConfig.Type) == "date" {
+		if config.SearchConfig.DateLayout == "" {
+			return fmt.Errorf("date_layout is required for date searches")
+		}
+		if _, err := ParseDatePattern(config.SearchConfig.Pattern); err != nil {
+			return fmt.Errorf("invalid date pattern: %w", err)
+		}
+	}
+
+	// XPath and CSS are two ways to write the same selector
+	if config.SearchConfig.XPath != "" && config.SearchConfig.CSS != "" {
+		return fmt.Errorf("xpath and css are mutually exclusive")
+	}
+	// The browser reports invalid CSS when fetching, catch it early for the http fetch mode
+	if config.SearchConfig.CSS != "" && config.FetchMode == "http" {
+		if _, err := cascadia.Parse(config.SearchConfig.CSS); err != nil {
+			return fmt.Errorf("invalid css selector: %w", err)
+		}
+	}
+
+	// Attributes are read from the selected element
+	if config.SearchConfig.Attribute != "" {
+		if config.SearchConfig.selector() == "" {
+			return fmt.Errorf("attribute requires xpath or css")
+		}
+		if strings.ToLower(config.SearchConfig.Type) == "element" {
+			return fmt.Errorf("attribute cannot be used with element searches")
+		}
+	}
+
+	// Checks and named searches mix types, case_insensitive only affects their string, regex and compound patterns
+	if config.SearchConfig.CaseInsensitive && len(config.SearchConfig.Checks) == 0 && len(config.SearchConfig.Searches) == 0 {
+		switch strings.ToLower(config.SearchConfig.Type) {
+		case "string", "regex", "compound":
+		default:
+			return fmt.Errorf("case_insensitive applies to string, regex and compound searches")
+		}
+	}
+
+	if config.SearchConfig.MatchAllElements && config.SearchConfig.selector() == "" && config.SearchConfig.JSONPath == "" {
+		return fmt.Errorf("match_all_elements requires xpath, css or jsonpath")
+	}
+
+	// JSONPath replaces the HTML extraction of the http fetch mode
+	if config.SearchConfig.JSONPath != "" {
+		if config.FetchMode != "http" {
+			return fmt.Errorf("jsonpath requires fetch_mode http")
+		}
+		if config.SearchConfig.needsHTML() {
+			return fmt.Errorf("jsonpath can't be combined with xpath, css, extract_regex, checks or element, availability, links and relation searches")
+		}
+		if _, err := parseJSONPath(config.SearchConfig.JSONPath); err != nil {
+			return fmt.Errorf("invalid jsonpath: %w", err)
+		}
+	}
+
+	// Regex extraction replaces the selector step, so the two can't be combined
+	if config.SearchConfig.ExtractRegex != "" {
+		if config.FetchMode == "github" {
+			return fmt.Errorf("extract_regex is not supported with fetch_mode github")
+		}
+		if config.SearchConfig.selector() != "" {
+			return fmt.Errorf("extract_regex can't be combined with xpath or css")
+		}
+		if strings.ToLower(config.SearchConfig.Type) == "element" {
+			return fmt.Errorf("extract_regex cannot be used with element searches")
+		}
+		re, err := regexp.Compile(config.SearchConfig.ExtractRegex)
+		if err != nil {
+			return fmt.Errorf("invalid extract_regex: %w", err)
+		}
+		if group := config.SearchConfig.ExtractGroup; group < 0 || group > re.NumSubexp() {
+			return fmt.Errorf("extract_group %d out of range, extract_regex has %d capture group(s)", group, re.NumSubexp())
+		}
+	}
+
+	if config.SearchConfig.NotifyIf != "" {
+		if _, err := ParseNotifyPredicate(config.SearchConfig.NotifyIf); err != nil {
+			return fmt.Errorf("invalid notify_if: %w", err)
+		}
+	}
+
+	if window := config.SearchConfig.Window; window != nil {
+		if window.Size <= 0 || window.MinCount <= 0 || window.MinCount > window.Size {
+			return fmt.Errorf("window requires 0 < min_count <= size")
+		}
+	}
+
+	switch config.SearchConfig.SortMatches {
+	case "":
+		config.SearchConfig.SortMatches = "none"
+	case "none", "asc", "desc", "numeric":
+	default:
+		return fmt.Errorf("invalid sort_matches %q, expected none, asc, desc or numeric", config.SearchConfig.SortMatches)
+	}
+
+	switch config.SearchConfig.MatchSelect {
+	case "":
+		config.SearchConfig.MatchSelect = "all"
+	case "all", "first", "last", "min", "max", "longest", "shortest":
+	default:
+		return fmt.Errorf("invalid match_select %q, expected all, first, last, min, max, longest or shortest", config.SearchConfig.MatchSelect)
+	}
+
+	switch config.SearchConfig.NotifyOn {
+	case "":
 		config.SearchConfig.NotifyOn = "found"
+	case "found", "not_found":
+	case "change":
+		if config.StateFile == "" {
+			config.StateFile = "uptodate-state.json"
+		}
+	default:
+		return fmt.Errorf("invalid notify_on %q, expected found, not_found or change", config.SearchConfig.NotifyOn)
+	}
+
+	// Validate the TLS policy up front, older versions than 1.2 must be allowed explicitly
+	if config.TLS == nil {
+		config.TLS = &TLSConfig{}
+	}
+	if config.TLS.MinVersion == "" {
+		config.TLS.MinVersion = "1.2"
+	}
+	if _, err := newTLSConfig(config.TLS); err != nil {
+		return err
+	}
+
+	if config.WarmupChecks < 0 || config.WarmupDuration < 0 {
+		return fmt.Errorf("warmup_checks and warmup_duration must not be negative")
+	}
+
+	switch config.TriggerMode {
+	case "":
+		if config.TriggerFile != "" {
+			config.TriggerMode = "exists"
+		}
+	case "exists", "modified":
+		if config.TriggerFile == "" {
+			return fmt.Errorf("trigger_mode requires trigger_file")
+		}
+	default:
+		return fmt.Errorf("invalid trigger_mode %q, expected exists or modified", config.TriggerMode)
+	}
+
+	switch {
+	case config.Interval == 0:
+		config.Interval = 300 // Default to 5 minutes
+	case config.Interval < 0:
+		return fmt.Errorf("interval must not be negative")
+	}
+
+	// A configured proxy replaces the proxy environment variables in both fetch modes
+	if config.Proxy != "" {
+		proxyURL, err := url.Parse(config.Proxy)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL")
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("invalid proxy scheme %q, expected http, https or socks5", proxyURL.Scheme)
+		}
+		if proxyURL.Host == "" {
+			return fmt.Errorf("proxy %s has no host", proxyURL.Redacted())
+		}
+		if proxyURL.User != nil && proxyURL.Scheme == "socks5" && config.FetchMode == "browser" {
+			return fmt.Errorf("chromium doesn't support socks5 proxy credentials, use an http proxy or fetch_mode http")
+		}
+	}
+
+	switch {
+	case config.Timeout == 0:
+		config.Timeout = 30
+	case config.Timeout < 0:
+		return fmt.Errorf("timeout must be positive")
+	}
+
+	if config.Jitter < 0 || config.Jitter >= 100 {
+		return fmt.Errorf("jitter must be a percentage from 0 to 99")
+	}
+
+	if adaptive := config.AdaptiveInterval; adaptive != nil {
+		if adaptive.Min <= 0 || adaptive.Max < adaptive.Min {
+			return fmt.Errorf("adaptive_interval requires 0 < min <= max")
+		}
+		if adaptive.Factor == 0 {
+			adaptive.Factor = 1.5
+		}
+		if adaptive.Factor < 1 {
+			return fmt.Errorf("adaptive_interval factor must be at least 1")
+		}
+	}
+
+	if retry := config.Retry; retry != nil {
+		if retry.MaxAttempts < 1 {
+			return fmt.Errorf("retry max_attempts must be at least 1")
+		}
+		if retry.BaseDelay < 0 {
+			return fmt.Errorf("retry base_delay must not be negative")
+		}
+		if retry.BaseDelay == 0 {
+			retry.BaseDelay = 1000
+		}
+	}
+
+	// Merge the cookies file into the configured cookies, leaving the shared slice untouched
+	if config.CookiesFile != "" {
+		cookies, err := parseCookiesFile(config.CookiesFile)
+		if err != nil {
+			return err
+		}
+		config.Cookies = slices.Concat(config.Cookies, cookies)
+		config.CookiesFile = ""
+	}
+	for i := range config.Cookies {
+		if config.Cookies[i].Name == "" || config.Cookies[i].Domain == "" {
+			return fmt.Errorf("cookies require a name and a domain")
+		}
+		if config.Cookies[i].Path == "" {
+			config.Cookies[i].Path = "/"
+		}
+	}
+
+	for name := range config.Headers {
+		if name == "" || strings.ContainsAny(name, " :\r\n") {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+	}
+
+	if config.MaxConsecutiveErrors < 0 {
+		return fmt.Errorf("max_consecutive_errors must not be negative")
+	}
+
+	// Apply defaults for DNS failure handling, -1 turns the retries off as 0 means the default
+	switch {
+	case config.DNSRetries == 0:
+		config.DNSRetries = 2
+	case config.DNSRetries < -1:
+		return fmt.Errorf("dns_retries must be -1 to disable or a positive number")
+	}
+	if config.DNSAlertAfter == 0 {
+		config.DNSAlertAfter = 3
+	}
+	if config.DNSAlertAfter < 0 {
+		return fmt.Errorf("dns_alert_after must not be negative")
+	}
+
+	// -1 fails on every Retry-After right away
+	switch {
+	case config.MaxRetryAfter == 0:
+		config.MaxRetryAfter = 60
+	case config.MaxRetryAfter < -1:
+		return fmt.Errorf("max_retry_after must be -1 to disable or a positive number")
+	}
+
+	switch {
+	case config.MaxBodySize == 0:
+		config.MaxBodySize = 10
+	case config.MaxBodySize < 0:
+		return fmt.Errorf("max_body_size must not be negative")
+	}
+
+	switch config.Dialogs.Action {
+	case "":
+		config.Dialogs.Action = "dismiss"
+	case "dismiss", "accept":
+	default:
+		return fmt.Errorf("invalid dialogs action %q, expected dismiss or accept", config.Dialogs.Action)
+	}
+
+	if config.DOMSettle < 0 || config.DOMSettleTimeout < 0 {
+		return fmt.Errorf("dom_settle and dom_settle_timeout must not be negative")
+	}
+	if config.DOMSettle > 0 && config.DOMSettleTimeout == 0 {
+		config.DOMSettleTimeout = 10000
+	}
+
+	// Only the browser renders elements after the load event
+	if config.WaitFor != "" && config.FetchMode != "browser" {
+		return fmt.Errorf("wait_for requires fetch_mode browser")
+	}
+	if config.RenderDelay < 0 {
+		return fmt.Errorf("render_delay must not be negative")
+	}
+	if config.RenderDelay > 0 && config.FetchMode != "browser" {
+		return fmt.Errorf("render_delay requires fetch_mode browser")
+	}
+	if config.ReusePage && config.FetchMode != "browser" {
+		return fmt.Errorf("reuse_page requires fetch_mode browser")
+	}
+
+	// Screenshots are taken by the browser and need somewhere to go
+	if config.Screenshot != nil {
+		if config.FetchMode != "browser" {
+			return fmt.Errorf("screenshot requires fetch_mode browser")
+		}
+		discord := config.Notifications.Discord
+		if config.Screenshot.Dir == "" && (discord == nil || !discord.AttachScreenshot) {
+			return fmt.Errorf("screenshot requires dir or discord attach_screenshot")
+		}
+	}
+	if discord := config.Notifications.Discord; discord != nil && discord.AttachScreenshot && config.Screenshot == nil {
+		return fmt.Errorf("discord attach_screenshot requires screenshot")
+	}
+
+	// The script's return value replaces extraction, so it can't be combined with selectors
+	if config.PreScript != "" && config.FetchMode != "browser" {
+		return fmt.Errorf("pre_script requires fetch_mode browser")
+	}
+	if config.PreScriptContent {
+		if config.PreScript == "" {
+			return fmt.Errorf("pre_script_content requires pre_script")
+		}
+		if config.SearchConfig.needsHTML() {
+			return fmt.Errorf("pre_script_content can't be combined with xpath, css, extract_regex, checks or element, availability, links and relation searches")
+		}
+	}
+	if config.WaitForTimeout < 0 {
+		return fmt.Errorf("wait_for_timeout must not be negative")
+	}
+	if config.WaitFor != "" && config.WaitForTimeout == 0 {
+		config.WaitForTimeout = 10000
+	}
+
+	if tunnel := config.SSHTunnel; tunnel != nil {
+		if tunnel.Host == "" || tunnel.User == "" || tunnel.KeyFile == "" ||
+			tunnel.LocalAddr == "" || tunnel.RemoteAddr == "" {
+			return fmt.Errorf("ssh_tunnel configuration is incomplete")
+		}
+		if tunnel.KnownHostsFile == "" && !tunnel.InsecureIgnoreHostKey {
+			return fmt.Errorf("ssh_tunnel requires known_hosts_file or insecure_ignore_host_key")
+		}
+		if !strings.Contains(tunnel.Host, ":") {
+			tunnel.Host += ":22"
+		}
 	}
 
 	// Ensure at least one notification method is available
 	notifications := config.Notifications
-	if notifications.Email == nil && notifications.Discord == nil && notifications.Slack == nil {
+	if notifications.Email == nil && notifications.Discord == nil && notifications.Slack == nil &&
+		notifications.Gotify == nil && notifications.File == nil && notifications.Exec == nil &&
+		notifications.Webhook == nil {
 		return fmt.Errorf("at least one notification method must be configured")
 	}
 
 	// Check all required SMTP fields and apply default port and subject
 	if notifications.Email != nil {
 		email := notifications.Email
-		if email.SMTPHost == "" || email.Username == "" || email.Password == "" ||
-			email.From == "" || email.To == "" {
+		if (email.SMTPHost == "" && len(email.SMTPServers) == 0) || email.From == "" {
 			return fmt.Errorf("email configuration is incomplete")
 		}
+		// Composing a sample message checks the from address and every recipient list
+		_, recipients, _, err := composeEmail(email, "", "", time.Now())
+		if err != nil {
+			return fmt.Errorf("invalid email configuration: %w", err)
+		}
+		if len(recipients) == 0 {
+			return fmt.Errorf("email configuration is incomplete: to, cc or bcc needs at least one address")
+		}
+		if email.TLSMode == "" {
+			email.TLSMode = "starttls"
+		}
+		if err := validateSMTPTLSMode(email.TLSMode); err != nil {
+			return err
+		}
+		if email.SMTPHost != "" {
+			if err := validateSMTPCredentials(email.SMTPHost, email.TLSMode, email.Username, email.Password); err != nil {
+				return err
+			}
+		}
 		if email.SMTPPort == 0 {
-			email.SMTPPort = 587
+			email.SMTPPort = defaultSMTPPort(email.TLSMode)
+		}
+		// Fallback servers inherit the top-level credentials and TLS settings unless they define their own
+		for i := range email.SMTPServers {
+			server := &email.SMTPServers[i]
+			if server.Host == "" {
+				return fmt.Errorf("smtp server %d: host is required", i+1)
+			}
+			if server.TLSMode == "" {
+				server.TLSMode = email.TLSMode
+			}
+			if err := validateSMTPTLSMode(server.TLSMode); err != nil {
+				return fmt.Errorf("smtp server %d: %w", i+1, err)
+			}
+			if server.Insecure == nil {
+				server.Insecure = &email.Insecure
+			}
+			if server.Port == 0 {
+				server.Port = defaultSMTPPort(server.TLSMode)
+			}
+			if server.Username == "" {
+				server.Username = email.Username
+				server.Password = email.Password
+			}
+			if err := validateSMTPCredentials(server.Host, server.TLSMode, server.Username, server.Password); err != nil {
+				return fmt.Errorf("smtp server %d: %w", i+1, err)
+			}
 		}
 		if email.Subject == "" {
 			email.Subject = "UpToDate Alert!"
 		}
 	}
 
-	if notifications.Discord != nil && notifications.Discord.WebhookURL == "" {
-		return fmt.Errorf("discord webhook URL is required")
+	if notifications.Discord != nil {
+		if notifications.Discord.WebhookURL == "" {
+			return fmt.Errorf("discord webhook URL is required")
+		}
+		switch notifications.Discord.Format {
+		case "":
+			notifications.Discord.Format = "text"
+		case "text", "embed":
+		default:
+			return fmt.Errorf("invalid discord format '%s' (must be 'text' or 'embed')", notifications.Discord.Format)
+		}
 	}
 
-	if notifications.Slack != nil && notifications.Slack.WebhookURL == "" {
-		return fmt.Errorf("slack webhook URL is required")
+	if notifications.Slack != nil {
+		if notifications.Slack.WebhookURL == "" {
+			return fmt.Errorf("slack webhook URL is required")
+		}
+		switch notifications.Slack.Format {
+		case "":
+			notifications.Slack.Format = "text"
+		case "text", "blocks":
+		default:
+			return fmt.Errorf("invalid slack format '%s' (must be 'text' or 'blocks')", notifications.Slack.Format)
+		}
 	}
 
+	if notifications.Gotify != nil {
+		if notifications.Gotify.ServerURL == "" || notifications.Gotify.Token == "" {
+			return fmt.Errorf("gotify server URL and token are required")
+		}
+		if notifications.Gotify.Priority == 0 {
+			notifications.Gotify.Priority = 5
+		}
+	}
+
+	if notifications.File != nil {
+		if notifications.File.Path == "" {
+			return fmt.Errorf("file notification path is required")
+		}
+		switch notifications.File.Format {
+		case "":
+			notifications.File.Format = "text"
+		case "text", "json":
+		default:
+			return fmt.Errorf("invalid file notification format %q, expected text or json", notifications.File.Format)
+		}
+	}
+
+	if notifications.Exec != nil {
+		if !notifications.Exec.Enabled {
+			return fmt.Errorf("exec notifier runs an external command and must be explicitly enabled")
+		}
+		if notifications.Exec.Command == "" {
+			return fmt.Errorf("exec notification command is required")
+		}
+		if notifications.Exec.Timeout == 0 {
+			notifications.Exec.Timeout = 30
+		}
+	}
+
+	if notifications.Webhook != nil {
+		if notifications.Webhook.URL == "" {
+			return fmt.Errorf("webhook url is required")
+		}
+		if notifications.Webhook.Method == "" {
+			notifications.Webhook.Method = http.MethodPost
+		}
+		if _, err := parseWebhookBody(notifications.Webhook.Body); err != nil {
+			return fmt.Errorf("invalid webhook body template: %w", err)
+		}
+	}
+
+	// Check per-channel timestamp formats
+	channelTimestamps := map[string]*TimestampConfig{}
+	if notifications.Email != nil {
+		channelTimestamps["email"] = &notifications.Email.TimestampConfig
+	}
+	if notifications.Discord != nil {
+		channelTimestamps["discord"] = &notifications.Discord.TimestampConfig
+	}
+	if notifications.Slack != nil {
+		channelTimestamps["slack"] = &notifications.Slack.TimestampConfig
+	}
+	if notifications.Gotify != nil {
+		channelTimestamps["gotify"] = &notifications.Gotify.TimestampConfig
+	}
+	if notifications.File != nil {
+		channelTimestamps["file"] = &notifications.File.TimestampConfig
+	}
+	if notifications.Exec != nil {
+		channelTimestamps["exec"] = &notifications.Exec.TimestampConfig
+	}
+	if notifications.Webhook != nil {
+		channelTimestamps["webhook"] = &notifications.Webhook.TimestampConfig
+	}
+	for channel, timestampConfig := range channelTimestamps {
+		if err := validateTimestampConfig(timestampConfig); err != nil {
+			return fmt.Errorf("%s: %w", channel, err)
+		}
+	}
+
+	// Bound every network send so a hung server can't stall the monitoring loop
+	channelTimeouts := map[string]*int{}
+	if notifications.Email != nil {
+		channelTimeouts["email"] = &notifications.Email.Timeout
+	}
+	if notifications.Discord != nil {
+		channelTimeouts["discord"] = &notifications.Discord.Timeout
+	}
+	if notifications.Slack != nil {
+		channelTimeouts["slack"] = &notifications.Slack.Timeout
+	}
+	if notifications.Gotify != nil {
+		channelTimeouts["gotify"] = &notifications.Gotify.Timeout
+	}
+	if notifications.Exec != nil {
+		channelTimeouts["exec"] = &notifications.Exec.Timeout
+	}
+	if notifications.Webhook != nil {
+		channelTimeouts["webhook"] = &notifications.Webhook.Timeout
+	}
+	for channel, timeout := range channelTimeouts {
+		if *timeout < 0 {
+			return fmt.Errorf("%s: timeout must not be negative", channel)
+		}
+		if *timeout == 0 {
+			*timeout = 10
+		}
+	}
+
+	// Per-channel notify_on replaces the top-level notify_on and errors for that channel
+	for channel, filter := range notifications.filters() {
+		for _, event := range filter.NotifyOn {
+			switch event {
+			case "found", "not_found", "error":
+			case "change":
+				if config.StateFile == "" {
+					config.StateFile = "uptodate-state.json"
+				}
+			default:
+				return fmt.Errorf("%s: invalid notify_on %q, expected found, not_found, change or error", channel, event)
+			}
+		}
+	}
+
+	// Rate limits may only reference configured channels
+	for channel, limit := range notifications.RateLimits {
+		if _, ok := channelTimestamps[channel]; !ok {
+			return fmt.Errorf("rate limit references unconfigured channel %q", channel)
+		}
+		if limit.PerMinute <= 0 {
+			return fmt.Errorf("%s rate limit: per_minute must be positive", channel)
+		}
+		if limit.Burst <= 0 {
+			limit.Burst = 1
+		}
+		switch limit.OnLimit {
+		case "":
+			limit.OnLimit = "wait"
+		case "wait", "drop":
+		default:
+			return fmt.Errorf("%s rate limit: invalid on_limit %q, expected wait or drop", channel, limit.OnLimit)
+		}
+		notifications.RateLimits[channel] = limit
+	}
+
+	// Dispatch order may only reference configured channels
+	for _, channel := range notifications.Order {
+		if _, ok := channelTimestamps[channel]; !ok {
+			return fmt.Errorf("notification order references unconfigured channel %q", channel)
+		}
+	}
+
+	if notifications.Dedup != nil && notifications.Dedup.Window < 0 {
+		return fmt.Errorf("dedup window must not be negative")
+	}
+	if notifications.Cooldown < 0 {
+		return fmt.Errorf("notification cooldown must not be negative")
+	}
+
+	// Heartbeat channels must exist
+	if heartbeat := notifications.Heartbeat; heartbeat != nil {
+		if heartbeat.IntervalHours <= 0 {
+			return fmt.Errorf("heartbeat interval_hours must be positive")
+		}
+		for _, channel := range heartbeat.Channels {
+			if _, ok := channelTimestamps[channel]; !ok {
+				return fmt.Errorf("heartbeat references unconfigured channel %q", channel)
+			}
+		}
+	}
+
+	// Escalation channels must exist and leave at least one channel for regular alerts
+	if escalation := notifications.Escalation; escalation != nil {
+		if len(escalation.Channels) == 0 {
+			return fmt.Errorf("escalation requires at least one channel")
+		}
+		if escalation.AfterErrors <= 0 && escalation.AfterMinutes <= 0 {
+			return fmt.Errorf("escalation requires after_errors or after_minutes")
+		}
+		for _, channel := range escalation.Channels {
+			if _, ok := channelTimestamps[channel]; !ok {
+				return fmt.Errorf("escalation references unconfigured channel %q", channel)
+			}
+		}
+		if len(channelTimestamps) <= len(escalation.Channels) {
+			return fmt.Errorf("escalation channels leave no channel for regular notifications")
+		}
+	}
+
+	return nil
+}
+
+// validateLinksSearch checks the URL filter and applies the default link source
+func validateLinksSearch(searchConfig *SearchConfig) error {
+	switch searchConfig.LinkSource {
+	case "":
+		searchConfig.LinkSource = "links"
+	case "links", "images":
+	default:
+		return fmt.Errorf("invalid link_source %q, expected links or images", searchConfig.LinkSource)
+	}
+
+	if searchConfig.Pattern != "" {
+		if _, err := regexp.Compile(searchConfig.Pattern); err != nil {
+			return fmt.Errorf("invalid link pattern: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateChecks checks every named check and applies the default search type
+// Checks replace the top-level pattern, so the two can't be combined
+func validateChecks(searchConfig *SearchConfig) error {
+	if searchConfig.Pattern != "" || searchConfig.selector() != "" || searchConfig.ExtractRegex != "" {
+		return fmt.Errorf("checks replace pattern, xpath, css and extract_regex, remove them from the search")
+	}
+	if searchConfig.Type != "" && strings.ToLower(searchConfig.Type) != "string" {
+		return fmt.Errorf("checks can't be combined with search type %q, set the type per check", searchConfig.Type)
+	}
+
+	for name, check := range searchConfig.Checks {
+		if check.Pattern == "" {
+			return fmt.Errorf("check %q: pattern is required", name)
+		}
+
+		switch strings.ToLower(check.Type) {
+		case "":
+			check.Type = "string"
+		case "string", "regex":
+		case "compound":
+			if _, err := ParseCompoundPattern(check.Pattern); err != nil {
+				return fmt.Errorf("check %q: invalid compound pattern: %w", name, err)
+			}
+		case "numeric":
+			if _, err := ParseNumericPattern(check.Pattern); err != nil {
+				return fmt.Errorf("check %q: invalid numeric pattern: %w", name, err)
+			}
+		default:
+			return fmt.Errorf("check %q: invalid type %q, expected string, regex, compound or numeric", name, check.Type)
+		}
+		searchConfig.Checks[name] = check
+	}
+	return nil
+}
+
+// validateRelation checks both sides and the operator of a relation search
+// The two XPaths replace the pattern and the usual extraction
+func validateRelation(searchConfig *SearchConfig) error {
+	relation := searchConfig.Relation
+	if relation == nil || relation.Left == "" || relation.Right == "" {
+		return fmt.Errorf("relation searches require relation left and right")
+	}
+	if searchConfig.Pattern != "" || searchConfig.selector() != "" || searchConfig.ExtractRegex != "" || len(searchConfig.Searches) > 0 {
+		return fmt.Errorf("relation searches can't be combined with pattern, xpath, css, extract_regex or searches")
+	}
+	if _, err := compareValues(0, relation.Operator, 0); err != nil {
+		return fmt.Errorf("invalid relation: %w", err)
+	}
+	return nil
+}
+
+// validateSearches checks every named search and applies its defaults
+// Searches replace the top-level pattern and share its extraction, so they can't be combined with checks
+func validateSearches(config *Config) error {
+	searchConfig := &config.SearchConfig
+	if searchConfig.Pattern != "" || len(searchConfig.Checks) > 0 {
+		return fmt.Errorf("searches replace pattern and checks, remove them from the search")
+	}
+	if searchConfig.Type != "" && strings.ToLower(searchConfig.Type) != "string" {
+		return fmt.Errorf("searches can't be combined with search type %q, set the type per search", searchConfig.Type)
+	}
+	if slices.Contains(config.Notifications.events(searchConfig.NotifyOn), "change") {
+		return fmt.Errorf("searches support notify_on found or not_found")
+	}
+
+	notifications := config.Notifications
+	configured := map[string]bool{
+		"email":   notifications.Email != nil,
+		"discord": notifications.Discord != nil,
+		"slack":   notifications.Slack != nil,
+		"gotify":  notifications.Gotify != nil,
+		"file":    notifications.File != nil,
+		"exec":    notifications.Exec != nil,
+		"webhook": notifications.Webhook != nil,
+	}
+
+	names := map[string]bool{}
+	for i := range searchConfig.Searches {
+		search := &searchConfig.Searches[i]
+		if search.Name == "" {
+			return fmt.Errorf("every search requires a name")
+		}
+		if names[search.Name] {
+			return fmt.Errorf("duplicate search name %q", search.Name)
+		}
+		names[search.Name] = true
+
+		if search.Pattern == "" {
+			return fmt.Errorf("search %q: pattern is required", search.Name)
+		}
+		switch strings.ToLower(search.Type) {
+		case "":
+			search.Type = "string"
+		case "string":
+		case "regex":
+			if _, err := regexp.Compile(search.Pattern); err != nil {
+				return fmt.Errorf("search %q: invalid regex pattern: %w", search.Name, err)
+			}
+		case "compound":
+			if _, err := ParseCompoundPattern(search.Pattern); err != nil {
+				return fmt.Errorf("search %q: invalid compound pattern: %w", search.Name, err)
+			}
+		case "numeric":
+			if _, err := ParseNumericPattern(search.Pattern); err != nil {
+				return fmt.Errorf("search %q: invalid numeric pattern: %w", search.Name, err)
+			}
+		default:
+			return fmt.Errorf("search %q: invalid type %q, expected string, regex, compound or numeric", search.Name, search.Type)
+		}
+
+		// Searches inherit the search's notify_on, change isn't meaningful when they share one page
+		switch search.NotifyOn {
+		case "":
+			search.NotifyOn = cmp.Or(searchConfig.NotifyOn, "found")
+		case "found", "not_found":
+		default:
+			return fmt.Errorf("search %q: invalid notify_on %q, expected found or not_found", search.Name, search.NotifyOn)
+		}
+
+		for _, channel := range search.Channels {
+			if !configured[channel] {
+				return fmt.Errorf("search %q: channel %q is not configured", search.Name, channel)
+			}
+		}
+		if search.Cooldown < 0 {
+			return fmt.Errorf("search %q: cooldown must not be negative", search.Name)
+		}
+	}
+	return nil
+}
+
+// validateGitHubConfig checks the watched repository and applies defaults
+// The release page doubles as the URL shown in notifications
+func validateGitHubConfig(config *Config) error {
+	githubConfig := config.GitHub
+	if githubConfig == nil || githubConfig.Repo == "" {
+		return fmt.Errorf("github.repo is required in github fetch mode")
+	}
+	if owner, repo, ok := strings.Cut(githubConfig.Repo, "/"); !ok || owner == "" || repo == "" {
+		return fmt.Errorf("github.repo must be in owner/repo form, got %q", githubConfig.Repo)
+	}
+
+	switch githubConfig.Source {
+	case "":
+		githubConfig.Source = "release"
+	case "release", "tag":
+	default:
+		return fmt.Errorf("invalid github.source %q, expected release or tag", githubConfig.Source)
+	}
+
+	if config.URL == "" {
+		config.URL = fmt.Sprintf("https://github.com/%s/releases", githubConfig.Repo)
+	}
+	if config.StateFile == "" {
+		config.StateFile = "uptodate-state.json"
+	}
+	return nil
+}
+
+// validateTimestampConfig checks that Discord timestamp markup uses a known style and loads the timezone
+func validateTimestampConfig(timestampConfig *TimestampConfig) error {
+	style, isDiscord := strings.CutPrefix(timestampConfig.TimeFormat, "discord:")
+	if isDiscord && (len(style) != 1 || !strings.Contains("tTdDfFR", style)) {
+		return fmt.Errorf("invalid discord timestamp style %q, expected one of t, T, d, D, f, F, R", style)
+	}
+
+	if timestampConfig.Timezone != "" {
+		location, err := time.LoadLocation(timestampConfig.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone: %w", err)
+		}
+		timestampConfig.location = location
+	}
 	return nil
 }
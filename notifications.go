@@ -2,68 +2,264 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"mime"
+	"mime/multipart"
+	"net"
 	"net/http"
+	"net/mail"
 	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"os"
+	"os/exec"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/template"
 	"time"
 )
 
 // NotificationService handles sending notifications
 // Coordinates sending messages across multiple notification channels
 type NotificationService struct {
-	config *Config
+	config    *Config
+	predicate *NotifyPredicate
+	previous  *string // First match of the last successful fetch, for notify_if
+
+	dnsFailures int       // Consecutive fetches that failed on DNS resolution
+	errorStreak int       // Consecutive failed fetches, for escalation
+	errorSince  time.Time // Time of the first error in the current streak
+
+	lastMatchHash string        // Hash of the last notified match set, for dedup
+	lastMatchTime time.Time     // When that match set was notified
+	lastState     string        // Outcome and matches of the last notification, for cooldown
+	lastStateTime time.Time     // When that state was notified
+	window        *resultWindow // Recent notify_on outcomes, when a window is configured
+
+	searches []*NotificationService // One service per named search, in config order
+
+	checks  int       // Fetches seen so far, for the warmup period
+	started time.Time // When monitoring started, for the warmup period
+
+	dryRun bool // Log rendered messages instead of sending them, set by -dry-run
 }
 
 // NewNotificationService creates a new notification service
 func NewNotificationService(config *Config) *NotificationService {
-	return &NotificationService{config: config}
+	ns := &NotificationService{config: config, started: time.Now()}
+
+	// notify_if has already been checked by validateConfig
+	if config.SearchConfig.NotifyIf != "" {
+		ns.predicate, _ = ParseNotifyPredicate(config.SearchConfig.NotifyIf)
+	}
+
+	if config.SearchConfig.Window != nil {
+		ns.window = newResultWindow(config.SearchConfig.Window.Size)
+	}
+
+	for _, search := range config.searchMonitors() {
+		ns.searches = append(ns.searches, NewNotificationService(search))
+	}
+
+	return ns
+}
+
+// SetDryRun makes the service and its named searches log messages instead of sending them
+func (ns *NotificationService) SetDryRun(dryRun bool) {
+	ns.dryRun = dryRun
+	for _, search := range ns.searches {
+		search.SetDryRun(dryRun)
+	}
+}
+
+// inherit takes over the runtime state of the service it replaces after a config reload
+// Error streaks, dedup and previous values carry on, named searches are matched by name
+func (ns *NotificationService) inherit(old *NotificationService) {
+	ns.previous = old.previous
+	ns.dnsFailures = old.dnsFailures
+	ns.errorStreak, ns.errorSince = old.errorStreak, old.errorSince
+	ns.lastMatchHash, ns.lastMatchTime = old.lastMatchHash, old.lastMatchTime
+	ns.lastState, ns.lastStateTime = old.lastState, old.lastStateTime
+	ns.checks, ns.started = old.checks, old.started
+
+	for _, search := range ns.searches {
+		for _, oldSearch := range old.searches {
+			if oldSearch.config.Name == search.config.Name {
+				search.inherit(oldSearch)
+			}
+		}
+	}
 }
 
 // SendNotification sends notifications based on fetch results
-// Attempts delivery to all configured channels and tracks results
+// Dispatches to configured channels in order and tracks results
 func (ns *NotificationService) SendNotification(result *Result) error {
-	// Skip sending if notification conditions are not met
-	if !ns.shouldNotify(result) {
+	// Track the error streak on every fetch so recovery resets escalation
+	escalated := ns.trackErrorStreak(result)
+	ns.checks++
+
+	// Named searches notify through their own services, the page's channels only report fetch errors
+	if result.Error == nil && len(ns.searches) > 0 {
+		ns.dnsFailures = 0
+		return ns.sendSearches(result)
+	}
+
+	// Skip sending if notification conditions are not met or no channel subscribes to the events
+	events := ns.notifyEvents(result)
+	channels := ns.subscribedChannels(events)
+	if len(channels) == 0 {
 		return nil
 	}
 
-	// Build message text and determine notification reason
-	message := ns.buildMessage(result)
-	reason := ns.getNotificationReason(result)
+	// Capture notification time once so every channel reports the same moment
+	now := time.Now()
+
+	// Hold back notifications while a new monitor warms up, its fetches still run and log
+	if ns.warmingUp(now) {
+		log.Printf("Skipping notification during warmup (%s)", ns.getNotificationReason(events))
+		return nil
+	}
+
+	// Suppress repeat alerts for an unchanged match set, however much the rest of the page changed
+	dedup := result.Error == nil && ns.config.Notifications.Dedup != nil
+	hash := matchSetHash(result.Matches)
+	if dedup && ns.isDuplicate(hash, now) {
+		log.Printf("Skipping notification, match set unchanged since %s", ns.lastMatchTime.Format(defaultTimeFormat))
+		return nil
+	}
+
+	// Remind of an unchanged state, errors included, at most once per cooldown
+	cooldown := ns.config.Notifications.Cooldown > 0
+	state := notificationState(result, escalated)
+	if cooldown && ns.inCooldown(state, now) {
+		log.Printf("Skipping notification, state unchanged since %s", ns.lastStateTime.Format(defaultTimeFormat))
+		return nil
+	}
+
+	// Only record once neither suppresses the alert, so one doesn't hide a later alert of the other
+	if dedup {
+		ns.lastMatchHash, ns.lastMatchTime = hash, now
+	}
+	if cooldown {
+		ns.lastState, ns.lastStateTime = state, now
+	}
+
+	reason := ns.getNotificationReason(events)
+	if escalated {
+		reason = fmt.Sprintf("%s, escalated after %d consecutive errors", reason, ns.errorStreak)
+	}
+
+	// Keep the screenshot of the notified page state, every message then names the file
+	ns.saveScreenshot(result, now)
+
+	// Split matches into separate notifications so each item can be acted on on its own
+	if ns.config.Notifications.NotifyPerMatch && result.Error == nil && len(result.Matches) > 1 {
+		var errors []error
+		for i, match := range result.Matches {
+			single := *result
+			single.Matches = []string{match}
+			if err := ns.dispatch(&single, channels, now, fmt.Sprintf("%s (match %d of %d)", reason, i+1, len(result.Matches)), escalated); err != nil {
+				errors = append(errors, err)
+			}
+		}
+		if len(errors) > 0 {
+			return fmt.Errorf("notification errors: %v", errors)
+		}
+		return nil
+	}
+
+	return ns.dispatch(result, channels, now, reason, escalated)
+}
+
+// SendHeartbeat notifies every channel that monitoring is still running, with a status line per monitor
+// Heartbeats skip notify_on filters, warmup, dedup and cooldown, they are sent on their own schedule
+func (ns *NotificationService) SendHeartbeat(lines []string) error {
+	return ns.dispatch(&Result{Heartbeat: lines}, ns.channels(), time.Now(), "heartbeat", false)
+}
+
+// sendSearches passes each named search's outcome to that search's notification service
+func (ns *NotificationService) sendSearches(result *Result) error {
+	var errors []error
+	for i, search := range result.Searches {
+		searchResult := &Result{
+			Found:      search.Found,
+			Content:    result.Content,
+			Matches:    search.Matches,
+			Response:   result.Response,
+			Screenshot: result.Screenshot,
+		}
+		if err := ns.searches[i].SendNotification(searchResult); err != nil {
+			errors = append(errors, fmt.Errorf("search %q: %w", search.Name, err))
+		}
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("notification errors: %v", errors)
+	}
+	return nil
+}
 
+// dispatch sends one notification to the given channels in order and tracks results
+func (ns *NotificationService) dispatch(result *Result, channels []notificationChannel, now time.Time, reason string, escalated bool) error {
 	// Initialize tracking for successful sends and errors
 	var errors []error
 	var sendChannels []string
 
-	// Try sending to each configured channel without stopping on failures
-	if ns.config.Notifications.Email != nil {
-		if err := ns.sendEmail(message); err != nil {
-			errors = append(errors, fmt.Errorf("email notification failed: %w", err))
-		} else {
-			sendChannels = append(sendChannels, "email")
-		}
+	// Fetch errors always reach every channel, pattern alerts may stop at the first success
+	stopOnFirstSuccess := ns.config.Notifications.StopOnFirstSuccess && result.Error == nil
+
+	if escalation := ns.config.Notifications.Escalation; escalation != nil && !escalated {
+		channels = slices.DeleteFunc(slices.Clone(channels), func(channel notificationChannel) bool {
+			return slices.Contains(escalation.Channels, channel.name)
+		})
 	}
+	sendErrors := make([]error, len(channels))
 
-	if ns.config.Notifications.Discord != nil {
-		if err := ns.sendDiscord(message); err != nil {
-			errors = append(errors, fmt.Errorf("discord notification failed: %w", err))
-		} else {
-			sendChannels = append(sendChannels, "discord")
+	if ns.config.Notifications.Concurrent && !stopOnFirstSuccess {
+		// Send to all channels at once, each goroutine owns its slot in sendErrors
+		var wg sync.WaitGroup
+		for i, channel := range channels {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sendErrors[i] = ns.deliver(channel, result, now)
+			}()
+		}
+		wg.Wait()
+	} else {
+		// Try each channel in order, falling through to the next on failure
+		for i, channel := range channels {
+			sendErrors[i] = ns.deliver(channel, result, now)
+			if sendErrors[i] == nil && stopOnFirstSuccess {
+				channels = channels[:i+1]
+				break
+			}
 		}
 	}
 
-	if ns.config.Notifications.Slack != nil {
-		if err := ns.sendSlack(message); err != nil {
-			errors = append(errors, fmt.Errorf("slack notification failed: %w", err))
+	// Collect outcomes in channel order so logs are stable regardless of completion timing
+	for i, channel := range channels {
+		if sendErrors[i] != nil {
+			errors = append(errors, fmt.Errorf("%s notification failed: %w", channel.name, sendErrors[i]))
 		} else {
-			sendChannels = append(sendChannels, "slack")
+			sendChannels = append(sendChannels, channel.name)
 		}
 	}
 
 	// Log successful deliveries and return any accumulated errors
-	if len(sendChannels) > 0 {
+	if len(sendChannels) > 0 && ns.dryRun {
+		log.Printf("Dry run, notification not sent via %v - Reason: %s", sendChannels, reason)
+	} else if len(sendChannels) > 0 {
 		log.Printf("Notification sent via %v - Reason: %s", sendChannels, reason)
 	}
 
@@ -74,69 +270,429 @@ func (ns *NotificationService) SendNotification(result *Result) error {
 	return nil
 }
 
-// shouldNotify determines if notifications should be sent
-// Returns true for errors or when pattern results match notify_on setting
-func (ns *NotificationService) shouldNotify(result *Result) bool {
-	// Send notifications for any fetch errors regardless of pattern results
-	if result.Error != nil {
+// warmingUp reports whether warmup_checks or warmup_duration still hold back notifications
+func (ns *NotificationService) warmingUp(now time.Time) bool {
+	return ns.checks <= ns.config.WarmupChecks ||
+		now.Sub(ns.started) < time.Duration(ns.config.WarmupDuration)*time.Second
+}
+
+// trackErrorStreak updates the consecutive error count and reports whether to escalate
+// Any successful fetch ends the streak
+func (ns *NotificationService) trackErrorStreak(result *Result) bool {
+	if result.Error == nil {
+		ns.errorStreak = 0
+		return false
+	}
+
+	if ns.errorStreak == 0 {
+		ns.errorSince = time.Now()
+	}
+	ns.errorStreak++
+
+	escalation := ns.config.Notifications.Escalation
+	if escalation == nil {
+		return false
+	}
+	if escalation.AfterErrors > 0 && ns.errorStreak >= escalation.AfterErrors {
 		return true
 	}
+	return escalation.AfterMinutes > 0 && time.Since(ns.errorSince) >= time.Duration(escalation.AfterMinutes)*time.Minute
+}
 
-	// Check notify_on setting to determine when to send for pattern results
-	notifyOn := ns.config.SearchConfig.NotifyOn
-	switch notifyOn {
-	case "found":
-		return result.Found
-	case "not_found":
-		return !result.Found
-	default:
-		return result.Found // Default behavior is notify when pattern found
+// isDuplicate reports whether a match set hash was already notified within the dedup window
+func (ns *NotificationService) isDuplicate(hash string, now time.Time) bool {
+	if hash != ns.lastMatchHash {
+		return false
 	}
+	window := ns.config.Notifications.Dedup.Window
+	return window == 0 || now.Sub(ns.lastMatchTime) < time.Duration(window)*time.Minute
 }
 
-// getNotificationReason returns reason for sending notification
-func (ns *NotificationService) getNotificationReason(result *Result) string {
+// inCooldown reports whether a state was already notified within the cooldown
+func (ns *NotificationService) inCooldown(state string, now time.Time) bool {
+	return state == ns.lastState && now.Sub(ns.lastStateTime) < time.Duration(ns.config.Notifications.Cooldown)*time.Minute
+}
+
+// notificationState describes what a notification reports: the fetch error, or the outcome and match set
+// An escalated error is a new state, so escalation is never held back by an earlier alert
+func notificationState(result *Result, escalated bool) string {
+	if result.Error != nil {
+		return fmt.Sprintf("error:%t:%s", escalated, result.Error)
+	}
+	return fmt.Sprintf("%t:%s", result.Found, matchSetHash(result.Matches))
+}
+
+// matchSetHash returns an order-independent hash of the matched values
+func matchSetHash(matches []string) string {
+	sorted := slices.Clone(matches)
+	slices.Sort(sorted)
+
+	hash := sha256.New()
+	for _, match := range sorted {
+		hash.Write([]byte(match))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// deliver sends a result to a single channel, respecting the channel's rate limit
+func (ns *NotificationService) deliver(channel notificationChannel, result *Result, now time.Time) error {
+	if ns.dryRun {
+		log.Printf("Dry run, %s would receive:\n%s", channel.name, ns.previewMessage(channel, result, now))
+		return nil
+	}
+
+	if err := acquireRateLimit(channel.name, ns.config.Notifications.RateLimits); err != nil {
+		return err
+	}
+
+	// Bound the send so a hung server can't stall the monitoring loop
+	ctx := context.Background()
+	if channel.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, channel.timeout)
+		defer cancel()
+	}
+
+	message := ns.renderMessage(channel, result, now)
+	if err := channel.send(ctx, message, result, now); err != nil {
+		// Connection deadlines can expire a moment before the context does
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+			return fmt.Errorf("timed out after %s", channel.timeout)
+		}
+		return err
+	}
+	return nil
+}
+
+// RenderedMessage is the message a channel would receive for a result
+type RenderedMessage struct {
+	Channel string
+	Message string
+}
+
+// RenderMessages renders the message for every configured channel without sending anything
+// Uses the same rendering path as SendNotification
+func (ns *NotificationService) RenderMessages(result *Result) []RenderedMessage {
+	now := time.Now()
+
+	var rendered []RenderedMessage
+	for _, channel := range ns.channels() {
+		rendered = append(rendered, RenderedMessage{Channel: channel.name, Message: ns.previewMessage(channel, result, now)})
+	}
+	return rendered
+}
+
+// previewMessage renders what a channel would receive for display
+// Rich formats are shown as the JSON payload the webhook receives
+func (ns *NotificationService) previewMessage(channel notificationChannel, result *Result, now time.Time) string {
+	message := ns.renderMessage(channel, result, now)
+
+	var payload any
+	switch {
+	case channel.name == "discord" && ns.config.Notifications.Discord.Format == "embed":
+		payload = ns.discordPayload(message, result, now)
+	case channel.name == "slack" && ns.config.Notifications.Slack.Format == "blocks":
+		payload = ns.slackPayload(message, result, now)
+	}
+	if payload != nil {
+		data, err := json.MarshalIndent(payload, "", "  ")
+		if err == nil {
+			message = string(data)
+		}
+	}
+	return message
+}
+
+// renderMessage builds the message for a channel using its timestamp settings
+func (ns *NotificationService) renderMessage(channel notificationChannel, result *Result, now time.Time) string {
+	return ns.buildMessage(result, formatTimestamp(now, channel.timestamp))
+}
+
+// notificationChannel is a configured channel with its timestamp settings, event filter and sender
+type notificationChannel struct {
+	name      string
+	timestamp TimestampConfig
+	filter    ChannelFilter
+	timeout   time.Duration // Limit for a single send, none when zero
+	send      func(ctx context.Context, message string, result *Result, now time.Time) error
+}
+
+// subscribes reports whether the channel receives an event
+// Channels without their own notify_on receive the top-level notify_on event and errors
+func (channel notificationChannel) subscribes(event, notifyOn string) bool {
+	if len(channel.filter.NotifyOn) == 0 {
+		return event == notifyOn || event == "error"
+	}
+	return slices.Contains(channel.filter.NotifyOn, event)
+}
+
+// subscribedChannels returns the channels in dispatch order that receive any of the events
+func (ns *NotificationService) subscribedChannels(events []string) []notificationChannel {
+	return slices.DeleteFunc(ns.channels(), func(channel notificationChannel) bool {
+		return !slices.ContainsFunc(events, func(event string) bool {
+			return channel.subscribes(event, ns.config.SearchConfig.NotifyOn)
+		})
+	})
+}
+
+// channels returns the configured notification channels in dispatch order
+// Channels listed in notifications.order come first, the rest keep their default order
+func (ns *NotificationService) channels() []notificationChannel {
+	notifications := ns.config.Notifications
+
+	seconds := func(timeout int) time.Duration { return time.Duration(timeout) * time.Second }
+
+	var channels []notificationChannel
+	if email := notifications.Email; email != nil {
+		channels = append(channels, notificationChannel{"email", email.TimestampConfig, email.ChannelFilter, seconds(email.Timeout),
+			func(ctx context.Context, message string, result *Result, now time.Time) error {
+				return ns.sendEmail(ctx, message, result, now)
+			}})
+	}
+	if discord := notifications.Discord; discord != nil {
+		channels = append(channels, notificationChannel{"discord", discord.TimestampConfig, discord.ChannelFilter, seconds(discord.Timeout), ns.sendDiscord})
+	}
+	if slack := notifications.Slack; slack != nil {
+		channels = append(channels, notificationChannel{"slack", slack.TimestampConfig, slack.ChannelFilter, seconds(slack.Timeout), ns.sendSlack})
+	}
+	if gotify := notifications.Gotify; gotify != nil {
+		channels = append(channels, notificationChannel{"gotify", gotify.TimestampConfig, gotify.ChannelFilter, seconds(gotify.Timeout),
+			func(ctx context.Context, message string, result *Result, _ time.Time) error {
+				return ns.sendGotify(ctx, message, result)
+			}})
+	}
+	if file := notifications.File; file != nil {
+		// Appends never block, a pipe without a reader fails right away
+		channels = append(channels, notificationChannel{"file", file.TimestampConfig, file.ChannelFilter, 0,
+			func(_ context.Context, message string, result *Result, now time.Time) error {
+				return ns.sendFile(message, result, now)
+			}})
+	}
+	if execConfig := notifications.Exec; execConfig != nil {
+		channels = append(channels, notificationChannel{"exec", execConfig.TimestampConfig, execConfig.ChannelFilter, seconds(execConfig.Timeout), ns.sendExec})
+	}
+	if webhook := notifications.Webhook; webhook != nil {
+		channels = append(channels, notificationChannel{"webhook", webhook.TimestampConfig, webhook.ChannelFilter, seconds(webhook.Timeout), ns.sendWebhook})
+	}
+
+	rank := func(name string) int {
+		if i := slices.Index(notifications.Order, name); i >= 0 {
+			return i
+		}
+		return len(notifications.Order)
+	}
+	sort.SliceStable(channels, func(i, j int) bool {
+		return rank(channels[i].name) < rank(channels[j].name)
+	})
+
+	return channels
+}
+
+// notifyEvents determines which notify_on events a result raises
+// Returns "error" for fetch errors, otherwise each subscribed event whose condition holds
+func (ns *NotificationService) notifyEvents(result *Result) []string {
+	// Hold back DNS failures until they persist, they are usually the resolver's fault
+	var dnsErr *DNSError
+	if errors.As(result.Error, &dnsErr) {
+		ns.dnsFailures++
+		if ns.dnsFailures < ns.config.DNSAlertAfter {
+			return nil
+		}
+		return []string{"error"}
+	}
+	ns.dnsFailures = 0
+
+	// Send notifications for any fetch errors regardless of pattern results
 	if result.Error != nil {
-		return "fetch error occurred"
+		return []string{"error"}
 	}
 
+	// Evaluate notify_if on every successful fetch so the previous value stays current
+	predicateHolds := ns.evaluatePredicate(result)
+
+	// Check the top-level notify_on and every event a channel subscribes to on its own
 	notifyOn := ns.config.SearchConfig.NotifyOn
-	switch notifyOn {
-	case "found":
-		if result.Found {
-			return "pattern found"
+	var events []string
+	for _, event := range ns.config.Notifications.events(notifyOn) {
+		var conditionHolds bool
+		switch event {
+		case "found":
+			conditionHolds = result.Found
+		case "not_found":
+			conditionHolds = !result.Found
+		case "change":
+			conditionHolds = ns.contentChanged(result)
+		default:
+			continue
 		}
-	case "not_found":
-		if !result.Found {
-			return "pattern not found"
+
+		// With a window configured the top-level condition must have held in enough recent checks
+		if event == notifyOn && ns.window != nil {
+			ns.window.add(conditionHolds)
+			conditionHolds = ns.window.count() >= ns.config.SearchConfig.Window.MinCount
 		}
-	default:
-		if result.Found {
-			return "pattern found (default)"
+
+		if conditionHolds && predicateHolds {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// contentChanged compares the fetched content with the last content stored in the state file
+// State errors are logged and count as unchanged so a broken state file can't cause alert storms
+func (ns *NotificationService) contentChanged(result *Result) bool {
+	store, err := loadStateStore(ns.config.StateFile)
+	if err != nil {
+		log.Printf("State error: %v", err)
+		return false
+	}
+
+	changed, err := store.changed(stateKey(ns.config), result.Content)
+	if err != nil {
+		log.Printf("State error: %v", err)
+	}
+	return changed
+}
+
+// resultWindow is a fixed-size ring buffer of recent check outcomes
+type resultWindow struct {
+	outcomes []bool
+	next     int
+}
+
+// newResultWindow creates an empty window holding the last size outcomes
+func newResultWindow(size int) *resultWindow {
+	return &resultWindow{outcomes: make([]bool, 0, size)}
+}
+
+// add records an outcome, replacing the oldest one once the window is full
+func (w *resultWindow) add(outcome bool) {
+	if len(w.outcomes) < cap(w.outcomes) {
+		w.outcomes = append(w.outcomes, outcome)
+		return
+	}
+	w.outcomes[w.next] = outcome
+	w.next = (w.next + 1) % len(w.outcomes)
+}
+
+// count returns how many outcomes in the window are true
+func (w *resultWindow) count() int {
+	total := 0
+	for _, outcome := range w.outcomes {
+		if outcome {
+			total++
 		}
 	}
-	return "unknown reason"
+	return total
+}
+
+// evaluatePredicate applies the notify_if predicate to the first match and remembers it
+// Always holds when no predicate is configured, never when there is no match to check
+func (ns *NotificationService) evaluatePredicate(result *Result) bool {
+	if ns.predicate == nil {
+		return true
+	}
+	if len(result.Matches) == 0 {
+		return false
+	}
+
+	current := result.Matches[0]
+	holds := ns.predicate.Evaluate(current, ns.previous)
+	ns.previous = &current
+	return holds
+}
+
+// getNotificationReason returns reason for sending notification
+func (ns *NotificationService) getNotificationReason(events []string) string {
+	reasons := map[string]string{
+		"error":     "fetch error occurred",
+		"found":     "pattern found",
+		"not_found": "pattern not found",
+		"change":    "content changed",
+	}
+
+	var parts []string
+	for _, event := range events {
+		parts = append(parts, reasons[event])
+	}
+	if len(parts) == 0 {
+		return "unknown reason"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// defaultTimeFormat is the timestamp layout used when a channel sets no time_format
+const defaultTimeFormat = "2006-01-02 15:04:05"
+
+// formatTimestamp renders the notification time using a channel's timestamp settings
+// Returns an empty string when the channel has timestamps disabled
+func formatTimestamp(t time.Time, timestampConfig TimestampConfig) string {
+	if timestampConfig.IncludeTimestamp != nil && !*timestampConfig.IncludeTimestamp {
+		return ""
+	}
+	if timestampConfig.location != nil {
+		t = t.In(timestampConfig.location)
+	}
+
+	switch {
+	case timestampConfig.TimeFormat == "":
+		return t.Format(defaultTimeFormat)
+	case strings.HasPrefix(timestampConfig.TimeFormat, "discord:"):
+		// Discord renders <t:unix:style> markup in the reader's local time
+		return fmt.Sprintf("<t:%d:%s>", t.Unix(), strings.TrimPrefix(timestampConfig.TimeFormat, "discord:"))
+	default:
+		return t.Format(timestampConfig.TimeFormat)
+	}
 }
 
 // buildMessage creates a notification message
-// Constructs timestamped message with pattern status and match details
-func (ns *NotificationService) buildMessage(result *Result) string {
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
+// Constructs message with pattern status and match details, prefixed by the timestamp if given
+func (ns *NotificationService) buildMessage(result *Result, timestamp string) string {
+	prefix := ""
+	if timestamp != "" {
+		prefix = fmt.Sprintf("[%s] ", timestamp)
+	}
+
+	// Name the target so alerts from a multi-target config can be told apart
+	page := ns.config.URL
+	if ns.config.Name != "" {
+		page = fmt.Sprintf("%s (%s)", ns.config.Name, ns.config.URL)
+	}
 
 	if result.Error != nil {
-		return fmt.Sprintf("[%s] Error monitoring %s: %s", timestamp, ns.config.URL, result.Error.Error())
+		return fmt.Sprintf("%sError monitoring %s: %s", prefix, page, result.Error.Error())
+	}
+	if result.Heartbeat != nil {
+		return fmt.Sprintf("%s%s\n\n%s", prefix, heartbeatTitle, strings.Join(result.Heartbeat, "\n"))
 	}
 
-	status := "NOT FOUND"
-	if result.Found {
-		status = "FOUND"
+	message := fmt.Sprintf("%s%s %s on %s",
+		prefix,
+		searchSubject(ns.config),
+		strings.ToUpper(searchStatus(ns.config, result.Found)),
+		page)
+
+	// List every named check so a partial outage shows which regions failed
+	if len(result.Checks) > 0 {
+		message += "\n\nChecks:"
+		for _, check := range result.Checks {
+			status := "not found"
+			if check.Found {
+				status = "found"
+			}
+			message += fmt.Sprintf("\n  %s: %s", check.Name, status)
+		}
 	}
 
-	message := fmt.Sprintf("[%s] Pattern '%s' %s on %s",
-		timestamp,
-		ns.config.SearchConfig.Pattern,
-		status,
-		ns.config.URL)
+	// With attribute_matches, show which branches of a compound pattern matched
+	if ns.config.SearchConfig.AttributeMatches && len(result.Patterns) > 0 {
+		message += "\n\nPatterns:"
+		for _, detail := range result.Patterns {
+			message += fmt.Sprintf("\n  %s", detail)
+		}
+	}
 
 	// Add specific regex matches to message when patterns are found
 	if result.Found && len(result.Matches) > 0 {
@@ -146,43 +702,359 @@ func (ns *NotificationService) buildMessage(result *Result) string {
 		}
 	}
 
+	if result.ScreenshotFile != "" {
+		message += fmt.Sprintf("\n\nScreenshot: %s", result.ScreenshotFile)
+	}
+
 	return message
 }
 
+// searchSubject describes what a search looks for, for logs and messages
+func searchSubject(config *Config) string {
+	if config.FetchMode == "github" {
+		return fmt.Sprintf("Latest %s of %s", config.GitHub.Source, config.GitHub.Repo)
+	}
+	if strings.ToLower(config.SearchConfig.Type) == "element" {
+		return fmt.Sprintf("Element '%s'", config.SearchConfig.selector())
+	}
+	if strings.ToLower(config.SearchConfig.Type) == "availability" {
+		return "Availability"
+	}
+	if len(config.SearchConfig.Checks) > 0 {
+		return fmt.Sprintf("%d checks", len(config.SearchConfig.Checks))
+	}
+	if len(config.SearchConfig.Searches) > 0 {
+		return fmt.Sprintf("%d searches", len(config.SearchConfig.Searches))
+	}
+	if relation := config.SearchConfig.Relation; strings.ToLower(config.SearchConfig.Type) == "relation" && relation != nil {
+		return fmt.Sprintf("Relation '%s %s %s'", relation.Left, relation.Operator, relation.Right)
+	}
+	if strings.ToLower(config.SearchConfig.Type) == "links" {
+		if config.SearchConfig.LinkSource == "images" {
+			return "New images"
+		}
+		return "New links"
+	}
+	return fmt.Sprintf("Pattern '%s'", config.SearchConfig.Pattern)
+}
+
+// searchStatus describes a search outcome, reporting presence for element searches
+func searchStatus(config *Config, found bool) string {
+	if config.FetchMode == "github" {
+		if found {
+			return "changed"
+		}
+		return "unchanged"
+	}
+	if strings.ToLower(config.SearchConfig.Type) == "element" {
+		if found {
+			return "present"
+		}
+		return "absent"
+	}
+	if strings.ToLower(config.SearchConfig.Type) == "availability" {
+		if found {
+			return "in stock"
+		}
+		return "out of stock"
+	}
+	if len(config.SearchConfig.Checks) > 0 {
+		if found {
+			return "all found"
+		}
+		return "not all found"
+	}
+	if len(config.SearchConfig.Searches) > 0 {
+		if found {
+			return "partly found"
+		}
+		return "none found"
+	}
+	if strings.ToLower(config.SearchConfig.Type) == "relation" {
+		if found {
+			return "holds"
+		}
+		return "does not hold"
+	}
+
+	if found {
+		return "found"
+	}
+	return "not found"
+}
+
 // sendEmail sends email notification
-// Connects to SMTP server and sends formatted email message
-func (ns *NotificationService) sendEmail(message string) error {
+// Tries each configured SMTP server in order until one accepts the message
+func (ns *NotificationService) sendEmail(ctx context.Context, message string, result *Result, now time.Time) error {
 	emailConfig := ns.config.Notifications.Email
+	var htmlMessage string
+	if emailConfig.HTML {
+		htmlMessage = ns.emailHTML(result, now)
+	}
+	from, envelope, body, err := composeEmail(emailConfig, message, htmlMessage, now)
+	if err != nil {
+		return err
+	}
 
-	auth := smtp.PlainAuth("", emailConfig.Username, emailConfig.Password, emailConfig.SMTPHost)
-	body := fmt.Sprintf("To: %s\r\nSubject: %s\r\n\r\n%s", emailConfig.To, emailConfig.Subject, message)
-	addr := fmt.Sprintf("%s:%d", emailConfig.SMTPHost, emailConfig.SMTPPort)
-	return smtp.SendMail(addr, auth, emailConfig.From, []string{emailConfig.To}, []byte(body))
+	var errors []error
+	for _, server := range smtpServers(emailConfig) {
+		// Servers without credentials, such as a local relay with tls_mode none, accept mail without AUTH
+		var auth smtp.Auth
+		if server.Username != "" {
+			auth = smtp.PlainAuth("", server.Username, server.Password, server.Host)
+		}
+		addr := fmt.Sprintf("%s:%d", server.Host, server.Port)
+		if err := sendMail(ctx, addr, server, auth, from, envelope, body); err != nil {
+			// The send timeout covers all servers, so there is no time left for the next one
+			if ctx.Err() != nil || os.IsTimeout(err) {
+				return fmt.Errorf("%s: %w", addr, err)
+			}
+			errors = append(errors, fmt.Errorf("%s: %w", addr, err))
+			continue
+		}
+
+		log.Printf("Email delivered via %s", addr)
+		return nil
+	}
+
+	return fmt.Errorf("all SMTP servers failed: %v", errors)
 }
 
-// DiscordWebhook represents a Discord webhook payload
-type DiscordWebhook struct {
-	Content string `json:"content"`
+// composeEmail builds the message with its RFC 5322 headers and returns it with the envelope sender and recipients
+// Bcc recipients receive the message without being listed in its headers
+// With an HTML rendering the body is multipart/alternative, so clients without HTML show the plain message
+func composeEmail(emailConfig *EmailConfig, message, htmlMessage string, now time.Time) (string, []string, []byte, error) {
+	from, err := mail.ParseAddress(emailConfig.From)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("invalid from address: %w", err)
+	}
+	if emailConfig.FromName != "" {
+		from.Name = emailConfig.FromName
+	}
+
+	var envelope []string
+	var headers strings.Builder
+	fmt.Fprintf(&headers, "From: %s\r\n", from)
+	for _, field := range []struct {
+		name string
+		list AddressList
+	}{{"To", emailConfig.To}, {"Cc", emailConfig.Cc}, {"Bcc", emailConfig.Bcc}} {
+		addresses, err := field.list.addresses()
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("invalid %s address: %w", strings.ToLower(field.name), err)
+		}
+		listed := make([]string, len(addresses))
+		for i, address := range addresses {
+			listed[i] = address.String()
+			envelope = append(envelope, address.Address)
+		}
+		if len(listed) > 0 && field.name != "Bcc" {
+			fmt.Fprintf(&headers, "%s: %s\r\n", field.name, strings.Join(listed, ", "))
+		}
+	}
+	fmt.Fprintf(&headers, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", emailConfig.Subject))
+	fmt.Fprintf(&headers, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	headers.WriteString("MIME-Version: 1.0\r\n")
+
+	if htmlMessage == "" {
+		headers.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		headers.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+		return from.Address, envelope, []byte(headers.String() + "\r\n" + message), nil
+	}
+
+	// Parts go from plainest to richest, clients show the last one they support
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", message},
+		{"text/html; charset=utf-8", htmlMessage},
+	} {
+		partWriter, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return "", nil, nil, err
+		}
+		if _, err := partWriter.Write([]byte(part.content)); err != nil {
+			return "", nil, nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", nil, nil, err
+	}
+	fmt.Fprintf(&headers, "Content-Type: multipart/alternative; boundary=%q\r\n", writer.Boundary())
+	return from.Address, envelope, append([]byte(headers.String()+"\r\n"), body.Bytes()...), nil
 }
 
-// sendDiscord sends Discord webhook notification
-// Posts JSON message to Discord webhook URL
-func (ns *NotificationService) sendDiscord(message string) error {
-	webhook := DiscordWebhook{Content: message}
+// sendMail works like smtp.SendMail, but the connection is bounded by the context's deadline
+// Implicit TLS wraps the whole connection, STARTTLS upgrades it and fails if the server doesn't offer it
+func sendMail(ctx context.Context, addr string, server SMTPServer, auth smtp.Auth, from string, to []string, msg []byte) error {
+	tlsConfig := &tls.Config{ServerName: server.Host, InsecureSkipVerify: server.Insecure != nil && *server.Insecure}
 
-	jsonData, err := json.Marshal(webhook)
+	var conn net.Conn
+	var err error
+	if server.TLSMode == "tls" {
+		dialer := tls.Dialer{Config: tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
 	if err != nil {
 		return err
 	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
 
-	resp, err := http.Post(ns.config.Notifications.Discord.WebhookURL, "application/json", bytes.NewBuffer(jsonData))
+	client, err := smtp.NewClient(conn, server.Host)
 	if err != nil {
 		return err
 	}
+	defer client.Close()
+
+	if server.TLSMode == "starttls" {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("server does not offer STARTTLS, set tls_mode to tls for implicit TLS or none to send unencrypted")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if ok, _ := client.Extension("AUTH"); ok && auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(msg); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// postJSON posts a json payload bounded by the context and returns the response status
+func postJSON(ctx context.Context, endpoint string, payload any) (int, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(jsonData))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// postFile posts a JSON payload together with a file as multipart form data, the way Discord takes attachments
+func postFile(ctx context.Context, endpoint string, payload any, fileName string, file []byte) (int, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("payload_json", string(jsonData)); err != nil {
+		return 0, err
+	}
+	part, err := writer.CreateFormFile("files[0]", fileName)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := part.Write(file); err != nil {
+		return 0, err
+	}
+	if err := writer.Close(); err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
 	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
 
-	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("discord webhook returned status %d", resp.StatusCode)
+// smtpServers returns the SMTP servers to try, primary host first
+func smtpServers(emailConfig *EmailConfig) []SMTPServer {
+	var servers []SMTPServer
+	if emailConfig.SMTPHost != "" {
+		servers = append(servers, SMTPServer{
+			Host:     emailConfig.SMTPHost,
+			Port:     emailConfig.SMTPPort,
+			Username: emailConfig.Username,
+			Password: emailConfig.Password,
+			TLSMode:  emailConfig.TLSMode,
+			Insecure: &emailConfig.Insecure,
+		})
+	}
+	return append(servers, emailConfig.SMTPServers...)
+}
+
+// DiscordWebhook represents a Discord webhook payload
+type DiscordWebhook struct {
+	Content string         `json:"content,omitempty"`
+	Embeds  []DiscordEmbed `json:"embeds,omitempty"`
+}
+
+// sendDiscord sends Discord webhook notification
+// Posts JSON message or embed to Discord webhook URL
+func (ns *NotificationService) sendDiscord(ctx context.Context, message string, result *Result, now time.Time) error {
+	discordConfig := ns.config.Notifications.Discord
+	payload := ns.discordPayload(message, result, now)
+
+	var status int
+	var err error
+	if discordConfig.AttachScreenshot && result.Screenshot != nil {
+		// Embeds show the uploaded screenshot as their image
+		for i := range payload.Embeds {
+			payload.Embeds[i].Image = &DiscordEmbedImage{URL: "attachment://" + screenshotAttachmentName}
+		}
+		status, err = postFile(ctx, discordConfig.WebhookURL, payload, screenshotAttachmentName, result.Screenshot)
+	} else {
+		status, err = postJSON(ctx, discordConfig.WebhookURL, payload)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Uploads are answered with the created message instead of no content
+	if status != http.StatusNoContent && status != http.StatusOK {
+		return fmt.Errorf("discord webhook returned status %d", status)
 	}
 
 	return nil
@@ -190,28 +1062,205 @@ func (ns *NotificationService) sendDiscord(message string) error {
 
 // SlackWebhook represents a Slack webhook payload
 type SlackWebhook struct {
-	Text string `json:"text"`
+	Text   string       `json:"text"`
+	Blocks []SlackBlock `json:"blocks,omitempty"`
 }
 
 // sendSlack sends Slack webhook notification
-// Posts JSON message to Slack webhook URL
-func (ns *NotificationService) sendSlack(message string) error {
-	webhook := SlackWebhook{Text: message}
+// Posts JSON message or blocks to Slack webhook URL
+func (ns *NotificationService) sendSlack(ctx context.Context, message string, result *Result, now time.Time) error {
+	status, err := postJSON(ctx, ns.config.Notifications.Slack.WebhookURL, ns.slackPayload(message, result, now))
+	if err != nil {
+		return err
+	}
+
+	if status != http.StatusOK {
+		return fmt.Errorf("slack webhook returned status %d", status)
+	}
+
+	return nil
+}
+
+// GotifyMessage represents a Gotify message payload
+type GotifyMessage struct {
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Priority int    `json:"priority"`
+}
+
+// sendGotify sends Gotify push notification
+// Posts JSON message to the Gotify message endpoint, raising priority for errors
+func (ns *NotificationService) sendGotify(ctx context.Context, message string, result *Result) error {
+	gotifyConfig := ns.config.Notifications.Gotify
+
+	priority := gotifyConfig.Priority
+	if result.Error != nil && priority < 8 {
+		priority = 8
+	}
+
+	payload := GotifyMessage{Title: "UpToDate Alert!", Message: message, Priority: priority}
+
+	endpoint := strings.TrimRight(gotifyConfig.ServerURL, "/") + "/message?token=" + url.QueryEscape(gotifyConfig.Token)
+	status, err := postJSON(ctx, endpoint, payload)
+	if err != nil {
+		return err
+	}
+
+	if status != http.StatusOK {
+		return fmt.Errorf("gotify server returned status %d", status)
+	}
+
+	return nil
+}
+
+// NotificationRecord is the structured form of a notification
+// Written by the file channel in json format and sent by the webhook channel
+type NotificationRecord struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Name       string    `json:"name,omitempty"`
+	URL        string    `json:"url"`
+	Pattern    string    `json:"pattern"`
+	Found      bool      `json:"found"`
+	Matches    []string  `json:"matches,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Status     int       `json:"status,omitempty"`     // HTTP status of the page, if a response was received
+	Screenshot string    `json:"screenshot,omitempty"` // Saved screenshot file, if any
+	Heartbeat  bool      `json:"heartbeat,omitempty"`  // A periodic "still running" notification, not a search result
+	Message    string    `json:"message"`
+}
+
+// notificationRecord builds the structured form of a notification
+func (ns *NotificationService) notificationRecord(message string, result *Result, now time.Time) NotificationRecord {
+	record := NotificationRecord{
+		Timestamp: now,
+		Name:      ns.config.Name,
+		URL:       ns.config.URL,
+		Pattern:   ns.config.SearchConfig.Pattern,
+		Found:     result.Found,
+		Matches:   result.Matches,
+		Message:   message,
+	}
+	if result.Error != nil {
+		record.Error = result.Error.Error()
+	}
+	if result.Response != nil {
+		record.Status = result.Response.StatusCode
+	}
+	record.Screenshot = result.ScreenshotFile
+	record.Heartbeat = result.Heartbeat != nil
+	return record
+}
+
+// sendFile appends notification to a file or named pipe
+// Uses a single non-blocking append so a pipe without a reader fails instead of hanging
+func (ns *NotificationService) sendFile(message string, result *Result, now time.Time) error {
+	fileConfig := ns.config.Notifications.File
+
+	line := message
+	if fileConfig.Format == "json" {
+		jsonData, err := json.Marshal(ns.notificationRecord(message, result, now))
+		if err != nil {
+			return err
+		}
+		line = string(jsonData)
+	}
 
-	jsonData, err := json.Marshal(webhook)
+	file, err := os.OpenFile(fileConfig.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE|syscall.O_NONBLOCK, 0644)
 	if err != nil {
 		return err
 	}
+	defer file.Close()
+
+	_, err = file.WriteString(line + "\n")
+	return err
+}
 
-	resp, err := http.Post(ns.config.Notifications.Slack.WebhookURL, "application/json", bytes.NewBuffer(jsonData))
+// sendExec runs the configured command with the message on stdin and result fields as env vars
+// Any exit code other than 0 is reported as a failed delivery, the command is killed on timeout
+func (ns *NotificationService) sendExec(ctx context.Context, message string, result *Result, now time.Time) error {
+	execConfig := ns.config.Notifications.Exec
+
+	cmd := exec.CommandContext(ctx, execConfig.Command, execConfig.Args...)
+	cmd.Stdin = strings.NewReader(message)
+	cmd.Env = append(os.Environ(),
+		"UPTODATE_NAME="+ns.config.Name,
+		"UPTODATE_URL="+ns.config.URL,
+		"UPTODATE_PATTERN="+ns.config.SearchConfig.Pattern,
+		"UPTODATE_FOUND="+strconv.FormatBool(result.Found),
+		"UPTODATE_MATCHES="+strings.Join(result.Matches, "\n"),
+		"UPTODATE_TIMESTAMP="+now.Format(time.RFC3339),
+	)
+	if result.Error != nil {
+		cmd.Env = append(cmd.Env, "UPTODATE_ERROR="+result.Error.Error())
+	}
+	if result.Response != nil {
+		cmd.Env = append(cmd.Env, "UPTODATE_STATUS="+strconv.Itoa(result.Response.StatusCode))
+	}
+	if result.ScreenshotFile != "" {
+		cmd.Env = append(cmd.Env, "UPTODATE_SCREENSHOT="+result.ScreenshotFile)
+	}
+	if result.Heartbeat != nil {
+		cmd.Env = append(cmd.Env, "UPTODATE_HEARTBEAT=true")
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("exec notifier failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// parseWebhookBody parses a webhook body template, nil when the default json body is used
+// Templates can use {{json .Field}} to insert a value as json
+func parseWebhookBody(body string) (*template.Template, error) {
+	if body == "" {
+		return nil, nil
+	}
+	return template.New("webhook").Funcs(template.FuncMap{
+		"json": func(value any) (string, error) {
+			data, err := json.Marshal(value)
+			return string(data), err
+		},
+	}).Parse(body)
+}
+
+// sendWebhook sends the notification record to the configured endpoint
+// Any 2xx status counts as delivered
+func (ns *NotificationService) sendWebhook(ctx context.Context, message string, result *Result, now time.Time) error {
+	webhookConfig := ns.config.Notifications.Webhook
+	record := ns.notificationRecord(message, result, now)
+
+	// The template was already checked by validateConfig
+	var body bytes.Buffer
+	tmpl, _ := parseWebhookBody(webhookConfig.Body)
+	if tmpl == nil {
+		if err := json.NewEncoder(&body).Encode(record); err != nil {
+			return err
+		}
+	} else if err := tmpl.Execute(&body, record); err != nil {
+		return fmt.Errorf("failed to render webhook body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, webhookConfig.Method, webhookConfig.URL, &body)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range webhookConfig.Headers {
+		req.Header.Set(name, value)
+	}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("slack webhook returned status %d", resp.StatusCode)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
 	}
+	defer resp.Body.Close()
 
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
 	return nil
 }
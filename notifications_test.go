@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeChannel is a channel that answers after a delay, failing when fail is set
+func fakeChannel(name string, delay time.Duration, fail bool) notificationChannel {
+	return notificationChannel{name: name, send: func(ctx context.Context, _ string, _ *Result, _ time.Time) error {
+		time.Sleep(delay)
+		if fail {
+			return fmt.Errorf("%s unavailable", name)
+		}
+		return nil
+	}}
+}
+
+func TestConcurrentDispatchKeepsChannelOrder(t *testing.T) {
+	// Channels without a default rate limit, so no send waits for a token
+	tests := []struct {
+		name       string
+		channels   []notificationChannel
+		wantSent   string
+		wantErrors []string
+	}{
+		{
+			name: "last channel finishes first",
+			channels: []notificationChannel{
+				fakeChannel("email", 60*time.Millisecond, false),
+				fakeChannel("file", 30*time.Millisecond, false),
+				fakeChannel("exec", 0, false),
+			},
+			wantSent: "[email file exec]",
+		},
+		{
+			name: "failures finish in reverse order",
+			channels: []notificationChannel{
+				fakeChannel("email", 50*time.Millisecond, true),
+				fakeChannel("file", 0, false),
+				fakeChannel("exec", 25*time.Millisecond, true),
+				fakeChannel("gotify", 10*time.Millisecond, false),
+				fakeChannel("webhook", 0, true),
+			},
+			wantSent:   "[file gotify]",
+			wantErrors: []string{"email", "exec", "webhook"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			log.SetOutput(&logs)
+			defer log.SetOutput(os.Stderr)
+
+			config := &Config{URL: "http://example.com"}
+			config.Notifications.Concurrent = true
+			ns := NewNotificationService(config)
+
+			err := ns.dispatch(&Result{Found: true}, test.channels, time.Now(), "pattern found", false)
+
+			if !strings.Contains(logs.String(), "Notification sent via "+test.wantSent) {
+				t.Errorf("log %q does not list sent channels as %s", logs.String(), test.wantSent)
+			}
+			if len(test.wantErrors) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			last := -1
+			for _, name := range test.wantErrors {
+				index := strings.Index(err.Error(), name+" notification failed")
+				if index < 0 {
+					t.Errorf("error %q does not report %s", err, name)
+				} else if index < last {
+					t.Errorf("error %q lists %s out of channel order", err, name)
+				}
+				last = index
+			}
+		})
+	}
+}
+
+func TestSlowChannelTimesOutWithoutBlockingOthers(t *testing.T) {
+	// The webhook only answers once the test is over, long after its timeout
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	path := filepath.Join(t.TempDir(), "alerts.log")
+	config := &Config{URL: "http://example.com", FetchMode: "http", SearchConfig: SearchConfig{Pattern: "Sale"}}
+	config.Notifications.Webhook = &WebhookConfig{URL: slow.URL, Timeout: 1}
+	config.Notifications.File = &FileConfig{Path: path}
+	config.Notifications.Order = []string{"webhook", "file"} // The slow channel goes first
+	if err := validateConfig(config); err != nil {
+		t.Fatalf("invalid config: %v", err)
+	}
+
+	start := time.Now()
+	err := NewNotificationService(config).SendNotification(&Result{Found: true, Matches: []string{"Sale"}})
+	elapsed := time.Since(start)
+
+	if elapsed > 2*time.Second {
+		t.Errorf("sending took %v, expected the webhook to be abandoned after its 1s timeout", elapsed)
+	}
+	if err == nil || !strings.Contains(err.Error(), "webhook notification failed: timed out after 1s") {
+		t.Errorf("error = %v, want the webhook reported as timed out", err)
+	}
+	if written, err := os.ReadFile(path); err != nil || !strings.Contains(string(written), "Sale") {
+		t.Errorf("file channel did not receive the alert: %q, %v", written, err)
+	}
+}
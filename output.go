@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ResultOutput is the serializable form of a fetch result
+// Shared by every -format so all serializations carry the same fields
+type ResultOutput struct {
+	XMLName   xml.Name            `json:"-" yaml:"-" xml:"result"`
+	Timestamp time.Time           `json:"timestamp" yaml:"timestamp" xml:"timestamp"`
+	Name      string              `json:"name,omitempty" yaml:"name,omitempty" xml:"name,omitempty"`
+	URL       string              `json:"url" yaml:"url" xml:"url"`
+	Type      string              `json:"type" yaml:"type" xml:"type"`
+	Pattern   string              `json:"pattern" yaml:"pattern" xml:"pattern"`
+	Found     bool                `json:"found" yaml:"found" xml:"found"`
+	Matches   []string            `json:"matches" yaml:"matches" xml:"matches>match"`
+	Error     string              `json:"error,omitempty" yaml:"error,omitempty" xml:"error,omitempty"`
+	Metrics   *PerformanceMetrics `json:"metrics,omitempty" yaml:"metrics,omitempty" xml:"metrics,omitempty"`
+	Checks    []CheckResult       `json:"checks,omitempty" yaml:"checks,omitempty" xml:"checks>check,omitempty"`
+	Searches  []SearchResult      `json:"searches,omitempty" yaml:"searches,omitempty" xml:"searches>search,omitempty"`
+	Patterns  []MatchDetail       `json:"patterns,omitempty" yaml:"patterns,omitempty" xml:"patterns>pattern,omitempty"`
+	Response  *ResponseInfo       `json:"response,omitempty" yaml:"response,omitempty" xml:"response,omitempty"`
+	Content   *string             `json:"content,omitempty" yaml:"content,omitempty" xml:"content,omitempty"` // Extracted content, only with -debug
+}
+
+// outputFormats lists the supported -format values
+var outputFormats = []string{"text", "json", "yaml", "xml"}
+
+// newResultOutput builds the serializable form of a result for the given config
+func newResultOutput(config *Config, result *Result) *ResultOutput {
+	output := &ResultOutput{
+		Timestamp: time.Now(),
+		Name:      config.Name,
+		URL:       config.URL,
+		Type:      config.SearchConfig.Type,
+		Pattern:   config.SearchConfig.Pattern,
+		Found:     result.Found,
+		Matches:   result.Matches,
+		Metrics:   result.Metrics,
+		Checks:    result.Checks,
+		Searches:  result.Searches,
+		Patterns:  result.Patterns,
+		Response:  result.Response,
+	}
+	if output.Matches == nil {
+		output.Matches = []string{}
+	}
+	if result.Error != nil {
+		output.Error = result.Error.Error()
+	}
+	return output
+}
+
+// writeResult serializes a result in the requested format
+// The text format prints a short plain summary
+func writeResult(w io.Writer, format string, config *Config, result *Result) error {
+	if format != "text" {
+		return encodeResult(w, format, newResultOutput(config, result))
+	}
+
+	var report strings.Builder
+	writeTextSummary(&report, config, result)
+	_, err := io.WriteString(w, report.String())
+	return err
+}
+
+// writeDebugResult reports a result together with the extracted content the search ran on
+// The text format prints the content verbatim between markers so whitespace stays visible
+func writeDebugResult(w io.Writer, format string, config *Config, result *Result) error {
+	if format != "text" {
+		output := newResultOutput(config, result)
+		output.Content = &result.Content
+		return encodeResult(w, format, output)
+	}
+
+	var report strings.Builder
+	writeTextSummary(&report, config, result)
+	fmt.Fprintf(&report, "----- extracted content (%d characters) -----\n", utf8.RuneCountInString(result.Content))
+	report.WriteString(result.Content)
+	report.WriteString("\n----- end of content -----\n")
+
+	_, err := io.WriteString(w, report.String())
+	return err
+}
+
+// writeTextSummary writes the label, URL, response, outcome and matches of a result as plain text
+func writeTextSummary(report *strings.Builder, config *Config, result *Result) {
+	fmt.Fprintf(report, "=== %s ===\n", config.Label())
+	fmt.Fprintf(report, "URL: %s\n", config.URL)
+	if response := result.Response; response != nil {
+		fmt.Fprintf(report, "Response: HTTP %d from %s in %.0fms\n", response.StatusCode, response.URL, response.Duration)
+	}
+	if result.Error != nil {
+		fmt.Fprintf(report, "Error: %v\n", result.Error)
+	} else {
+		fmt.Fprintf(report, "%s %s\n", searchSubject(config), searchStatus(config, result.Found))
+	}
+	for _, check := range result.Checks {
+		fmt.Fprintf(report, "Check %s: found=%t\n", check.Name, check.Found)
+	}
+	for _, search := range result.Searches {
+		fmt.Fprintf(report, "Search %s: found=%t, %d matches\n", search.Name, search.Found, len(search.Matches))
+	}
+	for _, detail := range result.Patterns {
+		fmt.Fprintf(report, "Pattern %s, %d matches\n", detail, len(detail.Matches))
+	}
+	for i, match := range result.Matches {
+		fmt.Fprintf(report, "  [%d] %q\n", i+1, match)
+	}
+}
+
+// encodeResult serializes a result output as json, yaml or xml
+func encodeResult(w io.Writer, format string, output *ResultOutput) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(output)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(output); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case "xml":
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		encoder := xml.NewEncoder(w)
+		encoder.Indent("", "  ")
+		if err := encoder.Encode(output); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// redactedSecret replaces secret values in -print-config output
+const redactedSecret = "REDACTED"
+
+// secretConfigKeys are config keys whose values are credentials
+// Webhook URLs count as secrets since anyone holding one can post to the channel
+var secretConfigKeys = map[string]bool{
+	"password": true, "token": true, "webhook_url": true, "value": true,
+}
+
+// secretConfigPaths are dotted config paths whose values are credentials, for keys that are only secret in one place
+// url is the plain target URL elsewhere, but the generic webhook's URL often embeds a token
+var secretConfigPaths = map[string]bool{
+	"notifications.webhook.url": true,
+}
+
+// secretHeaderMarkers identify request headers that carry credentials
+var secretHeaderMarkers = []string{"auth", "cookie", "token", "key", "secret"}
+
+// writeConfig prints the resolved configuration of every monitor as canonical json or yaml
+// Keys are sorted, and secrets are redacted unless showSecrets is set
+func writeConfig(w io.Writer, format string, monitors []*Config, showSecrets bool) error {
+	var value any = monitors
+	if len(monitors) == 1 {
+		value = monitors[0]
+	}
+
+	// Round trip through a generic tree so yaml uses the json keys and secrets can be found by key
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var tree any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return err
+	}
+	if !showSecrets {
+		redactSecrets(tree, "")
+	}
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(tree)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(tree); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("unsupported config format: %s, expected json or yaml", format)
+	}
+}
+
+// redactSecrets replaces credentials in a decoded config tree
+// The path holds the keys leading to the node, list positions are left out
+func redactSecrets(node any, path string) {
+	switch node := node.(type) {
+	case map[string]any:
+		for key, value := range node {
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+			switch {
+			case secretConfigKeys[key] || secretConfigPaths[keyPath]:
+				if value != "" {
+					node[key] = redactedSecret
+				}
+			case key == "proxy":
+				// Only the password of a proxy URL is secret
+				if proxyURL, err := url.Parse(fmt.Sprint(value)); err == nil && proxyURL.User != nil {
+					node[key] = proxyURL.Redacted()
+				}
+			case key == "headers":
+				headers, _ := value.(map[string]any)
+				for name := range headers {
+					if isSecretHeader(name) {
+						headers[name] = redactedSecret
+					}
+				}
+			default:
+				redactSecrets(value, keyPath)
+			}
+		}
+	case []any:
+		for _, value := range node {
+			redactSecrets(value, path)
+		}
+	}
+}
+
+// isSecretHeader reports whether a request header likely carries credentials
+func isSecretHeader(name string) bool {
+	name = strings.ToLower(name)
+	return slices.ContainsFunc(secretHeaderMarkers, func(marker string) bool {
+		return strings.Contains(name, marker)
+	})
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// tokenBucket is a thread-safe token bucket rate limiter
+// Tokens refill continuously at rate per second up to capacity
+type tokenBucket struct {
+	mu       sync.Mutex
+	tokens   float64
+	capacity float64
+	rate     float64
+	last     time.Time
+}
+
+// newTokenBucket creates a full bucket allowing perMinute sends with the given burst
+func newTokenBucket(perMinute, burst int) *tokenBucket {
+	return &tokenBucket{
+		tokens:   float64(burst),
+		capacity: float64(burst),
+		rate:     float64(perMinute) / 60,
+		last:     time.Now(),
+	}
+}
+
+// take consumes a token if one is available
+// Returns zero on success, otherwise how long until the next token is available
+func (b *tokenBucket) take() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// defaultRateLimits follow the documented webhook limits of each platform
+var defaultRateLimits = map[string]RateLimitConfig{
+	"discord": {PerMinute: 30, Burst: 5, OnLimit: "wait"},
+	"slack":   {PerMinute: 60, Burst: 1, OnLimit: "wait"},
+}
+
+// rateLimiters holds one bucket per channel and limit, shared by every notification service in the process
+// Keying by the limit too means a reloaded config or a target with its own limit gets a bucket of that size
+var rateLimiters = struct {
+	sync.Mutex
+	buckets map[string]*tokenBucket
+}{buckets: make(map[string]*tokenBucket)}
+
+// acquireRateLimit blocks or fails until the channel may send, depending on its on_limit policy
+// Channels without a configured or default limit are never limited
+func acquireRateLimit(channel string, limits map[string]RateLimitConfig) error {
+	limit, ok := limits[channel]
+	if !ok {
+		if limit, ok = defaultRateLimits[channel]; !ok {
+			return nil
+		}
+	}
+
+	key := fmt.Sprintf("%s/%d/%d", channel, limit.PerMinute, limit.Burst)
+	rateLimiters.Lock()
+	bucket, ok := rateLimiters.buckets[key]
+	if !ok {
+		bucket = newTokenBucket(limit.PerMinute, limit.Burst)
+		rateLimiters.buckets[key] = bucket
+	}
+	rateLimiters.Unlock()
+
+	for {
+		wait := bucket.take()
+		if wait == 0 {
+			return nil
+		}
+		if limit.OnLimit == "drop" {
+			return fmt.Errorf("rate limit of %d per minute reached, notification dropped", limit.PerMinute)
+		}
+		time.Sleep(wait)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Embed colors by outcome, as used by Discord's sidebar
+const (
+	embedColorError    = 0xE74C3C
+	embedColorFound    = 0x2ECC71
+	embedColorNotFound = 0x95A5A6
+)
+
+// Length limits of the rich message formats
+const (
+	discordFieldLimit = 1024
+	slackSectionLimit = 3000
+	contentSnippetLen = 300
+)
+
+// heartbeatTitle is the headline of heartbeat notifications
+const heartbeatTitle = "UpToDate is still running"
+
+// messageSection is a titled block of a notification, rendered as an embed field or a Slack section
+type messageSection struct {
+	Title string
+	Lines []string
+}
+
+// messageTitle is the headline of a notification, e.g. "Pattern 'x' FOUND"
+func (ns *NotificationService) messageTitle(result *Result) string {
+	if result.Error != nil {
+		return fmt.Sprintf("Error monitoring %s", ns.config.Label())
+	}
+	if result.Heartbeat != nil {
+		return heartbeatTitle
+	}
+	return fmt.Sprintf("%s %s", searchSubject(ns.config), strings.ToUpper(searchStatus(ns.config, result.Found)))
+}
+
+// messageSections lists the error, checks, compound sub-patterns and matches of a result
+// Change detection has no matches, so it shows the start of the new content instead
+func (ns *NotificationService) messageSections(result *Result) []messageSection {
+	if result.Error != nil {
+		return []messageSection{{Title: "Error", Lines: []string{result.Error.Error()}}}
+	}
+	if result.Heartbeat != nil {
+		return []messageSection{{Title: "Monitors", Lines: result.Heartbeat}}
+	}
+
+	var sections []messageSection
+	if len(result.Checks) > 0 {
+		section := messageSection{Title: "Checks"}
+		for _, check := range result.Checks {
+			status := "not found"
+			if check.Found {
+				status = "found"
+			}
+			section.Lines = append(section.Lines, fmt.Sprintf("%s: %s", check.Name, status))
+		}
+		sections = append(sections, section)
+	}
+
+	if ns.config.SearchConfig.AttributeMatches && len(result.Patterns) > 0 {
+		section := messageSection{Title: "Patterns"}
+		for _, detail := range result.Patterns {
+			section.Lines = append(section.Lines, detail.String())
+		}
+		sections = append(sections, section)
+	}
+
+	if result.Found && len(result.Matches) > 0 {
+		section := messageSection{Title: "Matches"}
+		for i, match := range result.Matches {
+			section.Lines = append(section.Lines, fmt.Sprintf("[%d] %s", i+1, match))
+		}
+		sections = append(sections, section)
+	} else if ns.config.SearchConfig.NotifyOn == "change" && result.Content != "" {
+		sections = append(sections, messageSection{Title: "Content", Lines: []string{truncateText(result.Content, contentSnippetLen)}})
+	}
+	return sections
+}
+
+// DiscordEmbed is a rich Discord message linking the monitored page
+type DiscordEmbed struct {
+	Title       string              `json:"title"`
+	URL         string              `json:"url,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Color       int                 `json:"color"`
+	Fields      []DiscordEmbedField `json:"fields,omitempty"`
+	Image       *DiscordEmbedImage  `json:"image,omitempty"`
+	Timestamp   string              `json:"timestamp,omitempty"`
+}
+
+// DiscordEmbedImage is the large image shown below an embed's fields
+type DiscordEmbedImage struct {
+	URL string `json:"url"`
+}
+
+// DiscordEmbedField is a titled block of an embed
+type DiscordEmbedField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// discordPayload builds the Discord webhook payload, an embed or the plain message depending on format
+func (ns *NotificationService) discordPayload(message string, result *Result, now time.Time) DiscordWebhook {
+	discordConfig := ns.config.Notifications.Discord
+	if discordConfig.Format != "embed" {
+		return DiscordWebhook{Content: message}
+	}
+
+	embed := DiscordEmbed{
+		Title: truncateText(ns.messageTitle(result), 256),
+		URL:   ns.config.URL,
+		Color: embedColorNotFound,
+	}
+	switch {
+	case result.Error != nil:
+		embed.Color = embedColorError
+	case result.Found:
+		embed.Color = embedColorFound
+	}
+	if ns.config.Name != "" && result.Error == nil {
+		embed.Description = ns.config.Name
+	}
+
+	// Discord shows embed timestamps in the reader's own time zone
+	if discordConfig.IncludeTimestamp == nil || *discordConfig.IncludeTimestamp {
+		embed.Timestamp = now.Format(time.RFC3339)
+	}
+
+	for _, section := range ns.messageSections(result) {
+		embed.Fields = append(embed.Fields, DiscordEmbedField{
+			Name:  section.Title,
+			Value: truncateText(strings.Join(section.Lines, "\n"), discordFieldLimit),
+		})
+	}
+	return DiscordWebhook{Embeds: []DiscordEmbed{embed}}
+}
+
+// SlackBlock is a Block Kit layout block
+type SlackBlock struct {
+	Type     string      `json:"type"`
+	Text     *SlackText  `json:"text,omitempty"`
+	Elements []SlackText `json:"elements,omitempty"`
+}
+
+// SlackText is a Block Kit text object
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackPayload builds the Slack webhook payload, blocks or the plain message depending on format
+// The plain message stays as the fallback Slack shows in notifications
+func (ns *NotificationService) slackPayload(message string, result *Result, now time.Time) SlackWebhook {
+	slackConfig := ns.config.Notifications.Slack
+	if slackConfig.Format != "blocks" {
+		return SlackWebhook{Text: message}
+	}
+
+	section := func(text string) SlackBlock {
+		return SlackBlock{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: truncateText(text, slackSectionLimit)}}
+	}
+
+	headline := fmt.Sprintf("*%s*", escapeSlack(ns.messageTitle(result)))
+	if ns.config.URL != "" {
+		headline = fmt.Sprintf("*<%s|%s>*", ns.config.URL, escapeSlack(ns.messageTitle(result)))
+	}
+	if ns.config.Name != "" && result.Error == nil {
+		headline += "\n" + escapeSlack(ns.config.Name)
+	}
+	blocks := []SlackBlock{section(headline)}
+
+	for _, messageSection := range ns.messageSections(result) {
+		lines := make([]string, len(messageSection.Lines))
+		for i, line := range messageSection.Lines {
+			lines[i] = "• " + escapeSlack(line)
+		}
+		blocks = append(blocks, section(fmt.Sprintf("*%s*\n%s", messageSection.Title, strings.Join(lines, "\n"))))
+	}
+
+	if timestamp := formatTimestamp(now, slackConfig.TimestampConfig); timestamp != "" {
+		blocks = append(blocks, SlackBlock{Type: "context", Elements: []SlackText{{Type: "mrkdwn", Text: escapeSlack(timestamp)}}})
+	}
+	return SlackWebhook{Text: message, Blocks: blocks}
+}
+
+// emailHTML renders a notification as an HTML email body with the page linked and each section as a list
+func (ns *NotificationService) emailHTML(result *Result, now time.Time) string {
+	var body strings.Builder
+	body.WriteString("<!DOCTYPE html>\n<html><body style=\"font-family: sans-serif\">\n")
+	fmt.Fprintf(&body, "<h2>%s</h2>\n", html.EscapeString(ns.messageTitle(result)))
+	if ns.config.Name != "" && result.Error == nil {
+		fmt.Fprintf(&body, "<p>%s</p>\n", html.EscapeString(ns.config.Name))
+	}
+	if ns.config.URL != "" {
+		fmt.Fprintf(&body, "<p><a href=\"%s\">%s</a></p>\n", html.EscapeString(ns.config.URL), html.EscapeString(ns.config.URL))
+	}
+
+	for _, section := range ns.messageSections(result) {
+		fmt.Fprintf(&body, "<h3>%s</h3>\n<ul>\n", html.EscapeString(section.Title))
+		for _, line := range section.Lines {
+			fmt.Fprintf(&body, "<li>%s</li>\n", html.EscapeString(line))
+		}
+		body.WriteString("</ul>\n")
+	}
+
+	if timestamp := formatTimestamp(now, ns.config.Notifications.Email.TimestampConfig); timestamp != "" {
+		fmt.Fprintf(&body, "<p style=\"color: #888\">%s</p>\n", html.EscapeString(timestamp))
+	}
+	body.WriteString("</body></html>\n")
+	return body.String()
+}
+
+// escapeSlack escapes the characters Slack's mrkdwn treats as control characters
+func escapeSlack(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// truncateText shortens text to at most limit characters, marking the cut with an ellipsis
+func truncateText(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	return string([]rune(text)[:limit-1]) + "…"
+}
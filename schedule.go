@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/sha256"
+	"log"
+	"math/rand/v2"
+	"time"
+)
+
+// pollSchedule decides when a monitor is fetched next
+// With adaptive_interval the interval drops to the minimum after a change and grows during quiet periods
+type pollSchedule struct {
+	config   *Config
+	interval time.Duration
+
+	lastContent [sha256.Size]byte // Hash of the last fetched content, for change detection
+	seen        bool
+}
+
+// newPollSchedule starts at the configured interval, kept within the adaptive bounds if any
+func newPollSchedule(config *Config) *pollSchedule {
+	interval := time.Duration(config.Interval) * time.Second
+
+	if adaptive := config.AdaptiveInterval; adaptive != nil {
+		interval = min(max(interval, adaptive.minInterval()), adaptive.maxInterval())
+	}
+	return &pollSchedule{config: config, interval: interval}
+}
+
+// next returns the delay before the following fetch, adapting it to the latest result
+// Failed fetches don't count as a change or as a quiet period
+func (p *pollSchedule) next(result *Result) time.Duration {
+	adaptive := p.config.AdaptiveInterval
+	if adaptive == nil || result.Error != nil {
+		return p.wait()
+	}
+
+	content := sha256.Sum256([]byte(result.Content))
+	changed := p.seen && content != p.lastContent
+	quiet := p.seen && !changed
+	p.lastContent, p.seen = content, true
+
+	switch {
+	case changed && p.interval != adaptive.minInterval():
+		p.interval = adaptive.minInterval()
+		log.Printf("%sContent changed, polling every %v", logPrefix(p.config), p.interval)
+	case quiet:
+		p.interval = min(time.Duration(float64(p.interval)*adaptive.Factor), adaptive.maxInterval())
+	}
+	return p.wait()
+}
+
+// wait returns the current interval, randomly varied by up to jitter percent in either direction
+// A jitter of 10 turns a 300s interval into anything from 270s to 330s
+func (p *pollSchedule) wait() time.Duration {
+	if p.config.Jitter == 0 {
+		return p.interval
+	}
+	spread := float64(p.interval) * float64(p.config.Jitter) / 100
+	return p.interval + time.Duration((rand.Float64()*2-1)*spread)
+}
+
+// minInterval returns the shortest polling interval
+func (a *AdaptiveIntervalConfig) minInterval() time.Duration {
+	return time.Duration(a.Min) * time.Second
+}
+
+// maxInterval returns the longest polling interval
+func (a *AdaptiveIntervalConfig) maxInterval() time.Duration {
+	return time.Duration(a.Max) * time.Second
+}
+
+// monitorTimers runs one timer per monitor and reports due monitors on a channel
+type monitorTimers struct {
+	due    chan int
+	done   chan struct{}
+	timers []*time.Timer
+}
+
+// startMonitorTimers starts a timer per schedule at its current interval, jittered if configured
+func startMonitorTimers(schedules []*pollSchedule) *monitorTimers {
+	t := &monitorTimers{due: make(chan int), done: make(chan struct{})}
+	for i, schedule := range schedules {
+		t.timers = append(t.timers, time.AfterFunc(schedule.wait(), func() {
+			select {
+			case t.due <- i:
+			case <-t.done:
+			}
+		}))
+	}
+	return t
+}
+
+// reset schedules a monitor's next tick after d
+func (t *monitorTimers) reset(i int, d time.Duration) {
+	t.timers[i].Reset(d)
+}
+
+// stop cancels all timers and discards ticks that already fired but were not received
+func (t *monitorTimers) stop() {
+	for _, timer := range t.timers {
+		timer.Stop()
+	}
+	close(t.done)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// screenshotAttachmentName is the file name screenshots are uploaded under
+const screenshotAttachmentName = "screenshot.png"
+
+// unsafeFileChars matches runs of characters kept out of screenshot file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// captureScreenshot takes a PNG of the page as it was searched
+// A failed capture is logged and doesn't fail the fetch
+func captureScreenshot(page *rod.Page, screenshotConfig *ScreenshotConfig) []byte {
+	png, err := page.Screenshot(screenshotConfig.FullPage, &proto.PageCaptureScreenshot{
+		Format: proto.PageCaptureScreenshotFormatPng,
+	})
+	if err != nil {
+		log.Printf("Failed to capture screenshot: %v", err)
+		return nil
+	}
+	return png
+}
+
+// screenshotFileName names a screenshot after the monitor and the notification time
+// e.g. "Camera-20260102-150405.png", the page's host is used for unnamed monitors
+func screenshotFileName(config *Config, now time.Time) string {
+	label := config.Name
+	if label == "" {
+		label = config.URL
+		if _, rest, ok := strings.Cut(label, "://"); ok {
+			label, _, _ = strings.Cut(rest, "/")
+		}
+	}
+	label = strings.Trim(unsafeFileChars.ReplaceAllString(label, "-"), "-.")
+	return fmt.Sprintf("%s-%s.png", label, now.Format("20060102-150405"))
+}
+
+// saveScreenshot writes the screenshot of a notified result to the screenshot directory
+// Runs once per notification, a dry run or a failed write only skips the file
+func (ns *NotificationService) saveScreenshot(result *Result, now time.Time) {
+	screenshotConfig := ns.config.Screenshot
+	if screenshotConfig == nil || screenshotConfig.Dir == "" || result.Screenshot == nil || ns.dryRun {
+		return
+	}
+
+	if err := os.MkdirAll(screenshotConfig.Dir, 0o755); err != nil {
+		log.Printf("Failed to create screenshot directory: %v", err)
+		return
+	}
+	path := filepath.Join(screenshotConfig.Dir, screenshotFileName(ns.config, now))
+	if err := os.WriteFile(path, result.Screenshot, 0o644); err != nil {
+		log.Printf("Failed to save screenshot: %v", err)
+		return
+	}
+	result.ScreenshotFile = path
+	log.Printf("Saved screenshot to %s", path)
+}
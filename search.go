@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// searchState remembers values between checks for searches that compare against earlier fetches
+// Shared by every fetch client that runs performSearch
+type searchState struct {
+	lastSeenDates map[string]time.Time       // Last parsed date per monitor for the date search type
+	seenLinks     map[string]map[string]bool // Links already reported per monitor for the links search type
+}
+
+// newSearchState creates empty search state
+func newSearchState() *searchState {
+	return &searchState{
+		lastSeenDates: make(map[string]time.Time),
+		seenLinks:     make(map[string]map[string]bool),
+	}
+}
+
+// pageData holds everything extracted from a page that searches run against
+type pageData struct {
+	content      string
+	metrics      *PerformanceMetrics
+	availability string   // schema.org availability from structured data, if any
+	links        []string // Link or image URLs for the links search type
+}
+
+// normalizeSpaces turns non-breaking spaces from &nbsp; into regular spaces
+// Keeps browser text consistent with the http fetch mode, so patterns typed with a normal space match both
+func normalizeSpaces(text string) string {
+	return strings.ReplaceAll(text, "\u00a0", " ")
+}
+
+// runChecks evaluates every named check, reading each check's text with checkContent
+// The result is found only when every check is found
+func (s *searchState) runChecks(config *Config, checkContent func(xpath string) (*string, error)) *Result {
+	names := make([]string, 0, len(config.SearchConfig.Checks))
+	for name := range config.SearchConfig.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := &Result{Found: true}
+	for _, name := range names {
+		check := config.SearchConfig.Checks[name]
+
+		// A region that isn't on the page counts as not found rather than an error
+		found := false
+		content, err := checkContent(check.XPath)
+		if err != nil {
+			return &Result{Error: fmt.Errorf("check %q: %w", name, err)}
+		}
+		if content != nil {
+			var matches []string
+			found, matches, err = s.performSearch(config.stateKey(), &pageData{content: *content}, &SearchConfig{
+				Type:            check.Type,
+				Pattern:         check.Pattern,
+				CaseInsensitive: config.SearchConfig.CaseInsensitive,
+			})
+			if err != nil {
+				return &Result{Error: fmt.Errorf("check %q: %w", name, err)}
+			}
+			for _, match := range matches {
+				result.Matches = append(result.Matches, fmt.Sprintf("%s: %s", name, match))
+			}
+		}
+
+		result.Checks = append(result.Checks, CheckResult{Name: name, Found: found})
+		result.Found = result.Found && found
+	}
+	return result
+}
+
+// runRelation compares the first number in two elements, found when the relation holds
+// An element missing from the page counts as not found, both values are reported as the match
+func runRelation(config *Config, elementText func(xpath string) (*string, error)) *Result {
+	relation := config.SearchConfig.Relation
+
+	var texts []string
+	var values []float64
+	for _, side := range []struct{ name, xpath string }{{"left", relation.Left}, {"right", relation.Right}} {
+		text, err := elementText(side.xpath)
+		if err != nil {
+			return &Result{Error: fmt.Errorf("relation %s: %w", side.name, err)}
+		}
+		if text == nil {
+			return &Result{Matches: []string{}}
+		}
+
+		value, err := extractNumber(*text)
+		if err != nil {
+			return &Result{Content: *text, Error: fmt.Errorf("relation %s: %w", side.name, err)}
+		}
+		texts = append(texts, strings.TrimSpace(*text))
+		values = append(values, value)
+	}
+
+	found, err := compareValues(values[0], relation.Operator, values[1])
+	if err != nil {
+		return &Result{Error: err}
+	}
+	return &Result{
+		Found:   found,
+		Content: strings.Join(texts, "\n"),
+		Matches: []string{fmt.Sprintf("left %s, right %s", texts[0], texts[1])},
+	}
+}
+
+// runSearches evaluates every named search against the same extracted page
+// The result is found when any search is found, each search is notified on its own
+func (s *searchState) runSearches(config *Config, data *pageData) *Result {
+	result := &Result{Content: data.content}
+	for _, search := range config.SearchConfig.Searches {
+		found, matches, err := s.performSearch(config.stateKey()+"#"+search.Name, data, &SearchConfig{
+			Type:             search.Type,
+			Pattern:          search.Pattern,
+			AttributeMatches: config.SearchConfig.AttributeMatches,
+			CaseInsensitive:  config.SearchConfig.CaseInsensitive,
+		})
+		if err != nil {
+			return &Result{Content: data.content, Error: fmt.Errorf("search %q: %w", search.Name, err)}
+		}
+		sortMatches(matches, config.SearchConfig.SortMatches)
+		matches = selectMatches(matches, config.SearchConfig.MatchSelect)
+
+		for _, match := range matches {
+			result.Matches = append(result.Matches, fmt.Sprintf("%s: %s", search.Name, match))
+		}
+		result.Searches = append(result.Searches, SearchResult{Name: search.Name, Found: found, Matches: matches})
+		result.Found = result.Found || found
+	}
+	return result
+}
+
+// compoundDetails reports the sub-patterns of a top-level compound search, nil for any other type
+func compoundDetails(searchConfig *SearchConfig, content string) []MatchDetail {
+	if strings.ToLower(searchConfig.Type) != "compound" {
+		return nil
+	}
+
+	compound, err := ParseCompoundPattern(searchConfig.Pattern)
+	if err != nil {
+		return nil
+	}
+	if searchConfig.CaseInsensitive {
+		compound.ignoreCase()
+	}
+
+	// The search itself already succeeded, so evaluating the same pattern again can't fail
+	details, _ := EvaluateCompoundPatternDetails(compound, content)
+	return details
+}
+
+// extractWithRegex returns the configured capture group of the first extract_regex match
+// A regex that matches nothing is handled like an XPath that matches nothing
+func extractWithRegex(raw string, searchConfig *SearchConfig) (string, error) {
+	re, err := regexp.Compile(searchConfig.ExtractRegex)
+	if err != nil {
+		return "", fmt.Errorf("invalid extract_regex: %w", err)
+	}
+
+	if match := re.FindStringSubmatch(raw); match != nil {
+		return match[searchConfig.ExtractGroup], nil
+	}
+
+	switch searchConfig.OnEmptyExtraction {
+	case "fallback-body":
+		return raw, nil
+	case "empty":
+		return "", nil
+	default:
+		return "", fmt.Errorf("extract_regex %q matched nothing", searchConfig.ExtractRegex)
+	}
+}
+
+// performSearch executes search based on configuration
+// Handles string, regex, compound, numeric, performance, date, availability, and links matching
+// The key names the monitor whose date and links state is compared against
+func (s *searchState) performSearch(key string, data *pageData, searchConfig *SearchConfig) (bool, []string, error) {
+	content := data.content
+
+	switch strings.ToLower(searchConfig.Type) {
+	case "string":
+		// Check if pattern text appears anywhere in content, reporting it as written on the page when ignoring case
+		match, found := searchConfig.Pattern, strings.Contains(content, searchConfig.Pattern)
+		if searchConfig.CaseInsensitive {
+			match, found = containsFold(content, searchConfig.Pattern)
+		}
+		matches := []string{}
+		if found {
+			matches = []string{match}
+		}
+		return found, matches, nil
+	case "regex":
+		// Compile regex and find all matches in content
+		pattern := searchConfig.Pattern
+		if searchConfig.CaseInsensitive {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, nil, fmt.Errorf("invalid regex pattern: %w", err)
+		}
+		matches := re.FindAllString(content, -1)
+		return len(matches) > 0, matches, nil
+	case "compound":
+		// Parse and evaluate boolean pattern expressions
+		compound, err := ParseCompoundPattern(searchConfig.Pattern)
+		if err != nil {
+			return false, nil, fmt.Errorf("invalid compound pattern: %w", err)
+		}
+		if searchConfig.CaseInsensitive {
+			compound.ignoreCase()
+		}
+		if !searchConfig.AttributeMatches {
+			return EvaluateCompoundPattern(compound, content)
+		}
+
+		// Report each match together with the sub-pattern it came from
+		found, sources, err := EvaluateCompoundPatternWithSources(compound, content)
+		if err != nil {
+			return false, nil, err
+		}
+		matches := make([]string, len(sources))
+		for i, source := range sources {
+			matches[i] = source.String()
+		}
+		return found, matches, nil
+	case "numeric":
+		// Compare the first number in the content, e.g. a price, against the threshold
+		return EvaluateNumericCondition(content, searchConfig.Pattern)
+	case "perf":
+		// Compare a page load metric against the configured threshold
+		return EvaluatePerfCondition(data.metrics, searchConfig.Pattern)
+	case "date":
+		// Parse the extracted date and compare it by age or against the last one seen
+		condition, err := ParseDatePattern(searchConfig.Pattern)
+		if err != nil {
+			return false, nil, fmt.Errorf("invalid date pattern: %w", err)
+		}
+
+		var lastSeen *time.Time
+		if date, ok := s.lastSeenDates[key]; ok {
+			lastSeen = &date
+		}
+
+		found, date, err := EvaluateDateCondition(content, searchConfig.DateLayout, condition, lastSeen)
+		if err != nil {
+			return false, nil, err
+		}
+		s.lastSeenDates[key] = date
+		return found, []string{date.Format(time.RFC1123)}, nil
+	case "availability":
+		// Apply built-in and configured stock heuristics
+		return EvaluateAvailability(content, data.availability, searchConfig.Availability)
+	case "links":
+		// Report links that were not on the page in any earlier check, the first check only takes the baseline
+		seen, ok := s.seenLinks[key]
+		if !ok {
+			seen = make(map[string]bool)
+			s.seenLinks[key] = seen
+		}
+		return EvaluateLinks(data.links, searchConfig.Pattern, seen, !ok)
+	default:
+		return false, nil, fmt.Errorf("unsupported search type: %s", searchConfig.Type)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// pauseSignals toggle monitoring between paused and running
+var pauseSignals = []os.Signal{syscall.SIGUSR1}
+
+// reloadSignals reload the config file without restarting
+var reloadSignals = []os.Signal{syscall.SIGHUP}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// pauseSignals is empty because Windows has no user-defined signals
+var pauseSignals []os.Signal
+
+// reloadSignals is empty because Windows has no SIGHUP
+var reloadSignals []os.Signal
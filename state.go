@@ -0,0 +1,125 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// stateStore persists the content hash of every notify_on "change" monitor between runs,
+// and the last seen version of every github monitor
+type stateStore struct {
+	path string
+
+	mu     sync.Mutex
+	hashes map[string]string // Content hash or version per monitor key
+}
+
+// stateStores holds one store per state file, shared by every notification service in the process
+var stateStores = struct {
+	sync.Mutex
+	stores map[string]*stateStore
+}{stores: make(map[string]*stateStore)}
+
+// loadStateStore returns the store for a state file, reading it on first use
+// A missing file is an empty store, so the first run establishes a baseline
+func loadStateStore(path string) (*stateStore, error) {
+	stateStores.Lock()
+	defer stateStores.Unlock()
+
+	if store, ok := stateStores.stores[path]; ok {
+		return store, nil
+	}
+
+	store := &stateStore{path: path, hashes: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	default:
+		if err := json.Unmarshal(data, &store.hashes); err != nil {
+			return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+		}
+	}
+
+	stateStores.stores[path] = store
+	return store, nil
+}
+
+// changed records the content for a key and reports whether it differs from the stored content
+// A key without stored content only records the baseline and reports no change
+func (s *stateStore) changed(key, content string) (bool, error) {
+	sum := sha256.Sum256([]byte(content))
+	hash := hex.EncodeToString(sum[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, seen := s.hashes[key]
+	if seen && previous == hash {
+		return false, nil
+	}
+
+	s.hashes[key] = hash
+	return seen, s.save()
+}
+
+// swap records the value for a key and returns the value stored before, if any
+func (s *stateStore) swap(key, value string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, seen := s.hashes[key]
+	if seen && previous == value {
+		return previous, true, nil
+	}
+
+	s.hashes[key] = value
+	return previous, seen, s.save()
+}
+
+// save writes the store through a temporary file so a crash never leaves a truncated state file
+func (s *stateStore) save() error {
+	data, err := json.MarshalIndent(s.hashes, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".uptodate-state-*")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// stateKey identifies a monitor in the state file
+// Includes the extraction settings so different searches on one page don't share a baseline
+func stateKey(config *Config) string {
+	// Prefix CSS selectors so existing XPath keys stay valid
+	selector := config.SearchConfig.XPath
+	if config.SearchConfig.CSS != "" {
+		selector = "css:" + config.SearchConfig.CSS
+	}
+	if config.SearchConfig.Attribute != "" {
+		selector += "@" + config.SearchConfig.Attribute
+	}
+	return fmt.Sprintf("%s|%s|%s|%s", config.Name, config.URL, selector, config.SearchConfig.ExtractRegex)
+}
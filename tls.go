@@ -0,0 +1,44 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// tlsVersions maps min_version values to crypto/tls versions
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// newTLSConfig builds the client TLS settings for the http fetch mode
+// Cipher suites are looked up by their Go names, insecure ones included so legacy endpoints can be diagnosed
+func newTLSConfig(tlsConfig *TLSConfig) (*tls.Config, error) {
+	minVersion, ok := tlsVersions[tlsConfig.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("invalid tls min_version %q, expected 1.0, 1.1, 1.2 or 1.3", tlsConfig.MinVersion)
+	}
+	config := &tls.Config{MinVersion: minVersion}
+
+	suites := slices.Concat(tls.CipherSuites(), tls.InsecureCipherSuites())
+	for _, name := range tlsConfig.CipherSuites {
+		index := slices.IndexFunc(suites, func(suite *tls.CipherSuite) bool { return suite.Name == name })
+		if index < 0 {
+			return nil, fmt.Errorf("unknown tls cipher suite %q", name)
+		}
+		config.CipherSuites = append(config.CipherSuites, suites[index].ID)
+	}
+	return config, nil
+}
+
+// describeTLS summarizes the TLS settings for the startup log
+func describeTLS(tlsConfig *TLSConfig) string {
+	if len(tlsConfig.CipherSuites) == 0 {
+		return fmt.Sprintf("minimum version %s, default cipher suites", tlsConfig.MinVersion)
+	}
+	return fmt.Sprintf("minimum version %s, cipher suites %s", tlsConfig.MinVersion, strings.Join(tlsConfig.CipherSuites, ", "))
+}
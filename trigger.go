@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// triggerGate lets an external process decide when scheduled fetches run through a trigger file
+// In "exists" mode fetches run while the file exists, in "modified" mode once per change of its modification time
+type triggerGate struct {
+	path         string
+	mode         string
+	lastModified time.Time // Modification time the last fetch ran for, in modified mode
+}
+
+// newTriggerGate creates the gate for a monitor, nil when no trigger file is configured
+func newTriggerGate(config *Config) *triggerGate {
+	if config.TriggerFile == "" {
+		return nil
+	}
+	return &triggerGate{path: config.TriggerFile, mode: config.TriggerMode}
+}
+
+// ready reports whether the next fetch may run, with the reason when it may not
+func (t *triggerGate) ready() (bool, string) {
+	info, err := os.Stat(t.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, fmt.Sprintf("trigger file %s does not exist", t.path)
+	}
+	if err != nil {
+		return false, fmt.Sprintf("trigger file unreadable: %v", err)
+	}
+
+	if t.mode == "modified" {
+		if !info.ModTime().After(t.lastModified) {
+			return false, fmt.Sprintf("trigger file %s unchanged", t.path)
+		}
+		t.lastModified = info.ModTime()
+	}
+	return true, ""
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHTunnel forwards connections on a local address to a remote address through an SSH host
+// The SSH connection is re-established on demand when it drops
+type SSHTunnel struct {
+	config       *SSHTunnelConfig
+	clientConfig *ssh.ClientConfig
+	listener     net.Listener
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+// StartSSHTunnel connects to the SSH host and starts forwarding the local address
+func StartSSHTunnel(tunnelConfig *SSHTunnelConfig) (*SSHTunnel, error) {
+	clientConfig, err := newSSHClientConfig(tunnelConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	tunnel := &SSHTunnel{config: tunnelConfig, clientConfig: clientConfig}
+
+	// Connect up front so a misconfigured tunnel fails at startup
+	if _, err := tunnel.connect(); err != nil {
+		return nil, err
+	}
+
+	tunnel.listener, err = net.Listen("tcp", tunnelConfig.LocalAddr)
+	if err != nil {
+		tunnel.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", tunnelConfig.LocalAddr, err)
+	}
+
+	go tunnel.acceptLoop()
+	log.Printf("SSH tunnel %s -> %s via %s established", tunnelConfig.LocalAddr, tunnelConfig.RemoteAddr, tunnelConfig.Host)
+	return tunnel, nil
+}
+
+// Close stops forwarding and disconnects from the SSH host
+func (t *SSHTunnel) Close() {
+	if t.listener != nil {
+		t.listener.Close()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client != nil {
+		t.client.Close()
+		t.client = nil
+	}
+}
+
+// acceptLoop forwards every local connection until the listener is closed
+func (t *SSHTunnel) acceptLoop() {
+	for {
+		local, err := t.listener.Accept()
+		if err != nil {
+			return
+		}
+		go t.forward(local)
+	}
+}
+
+// forward copies data between a local connection and the remote address
+func (t *SSHTunnel) forward(local net.Conn) {
+	defer local.Close()
+
+	remote, err := t.dialRemote()
+	if err != nil {
+		log.Printf("SSH tunnel error: %v", err)
+		return
+	}
+	defer remote.Close()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(remote, local)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(local, remote)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// dialRemote opens a connection to the remote address, reconnecting once if the SSH session died
+func (t *SSHTunnel) dialRemote() (net.Conn, error) {
+	client, err := t.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := client.Dial("tcp", t.config.RemoteAddr)
+	if err == nil {
+		return conn, nil
+	}
+
+	log.Printf("SSH tunnel dial failed, reconnecting: %v", err)
+	t.reset(client)
+	if client, err = t.connect(); err != nil {
+		return nil, err
+	}
+	return client.Dial("tcp", t.config.RemoteAddr)
+}
+
+// connect returns the current SSH client, dialing the SSH host if there is none
+func (t *SSHTunnel) connect() (*ssh.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.client != nil {
+		return t.client, nil
+	}
+
+	client, err := ssh.Dial("tcp", t.config.Host, t.clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SSH host %s: %w", t.config.Host, err)
+	}
+	t.client = client
+	return client, nil
+}
+
+// reset drops a broken SSH client so the next connect dials again
+func (t *SSHTunnel) reset(broken *ssh.Client) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.client == broken {
+		t.client.Close()
+		t.client = nil
+	}
+}
+
+// newSSHClientConfig builds key authentication and host key checking from the tunnel config
+func newSSHClientConfig(tunnelConfig *SSHTunnelConfig) (*ssh.ClientConfig, error) {
+	key, err := os.ReadFile(tunnelConfig.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH key: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH key: %w", err)
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if !tunnelConfig.InsecureIgnoreHostKey {
+		if hostKeyCallback, err = knownhosts.New(tunnelConfig.KnownHostsFile); err != nil {
+			return nil, fmt.Errorf("failed to load known hosts: %w", err)
+		}
+	}
+
+	return &ssh.ClientConfig{
+		User:            tunnelConfig.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         15 * time.Second,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// version is the build version, injected at build time via -ldflags "-X main.version=..."
+var version = "dev"
+
+// releaseRepo is the repository UpToDate releases are published in
+const releaseRepo = "paul-eff/UpToDate"
+
+// GitHubRelease represents the fields used from the GitHub releases API
+type GitHubRelease struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+}
+
+// checkForUpdate queries GitHub for the latest release and reports whether it is newer
+func checkForUpdate() (string, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	release, err := fetchLatestRelease(client, releaseRepo, "")
+	if err != nil {
+		return "", err
+	}
+
+	// Development builds can't be compared, so just report the latest release
+	if version == "dev" {
+		return fmt.Sprintf("Running a development build, latest release is %s (%s)", release.TagName, release.HTMLURL), nil
+	}
+
+	if compareVersions(release.TagName, version) > 0 {
+		return fmt.Sprintf("A newer version is available: %s (running %s) - %s", release.TagName, version, release.HTMLURL), nil
+	}
+	return fmt.Sprintf("UpToDate %s is up to date", version), nil
+}
+
+// compareVersions compares two dotted version strings, ignoring a leading "v"
+// Returns 1 if a is newer, -1 if b is newer and 0 if they are equal
+func compareVersions(a, b string) int {
+	partsA := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	partsB := strings.Split(strings.TrimPrefix(b, "v"), ".")
+
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var numA, numB int
+		if i < len(partsA) {
+			numA, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			numB, _ = strconv.Atoi(partsB[i])
+		}
+
+		if numA > numB {
+			return 1
+		}
+		if numA < numB {
+			return -1
+		}
+	}
+	return 0
+}